@@ -0,0 +1,147 @@
+package configflow
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ArchiveSource loads configuration fragments from a zip, tar or tar.gz
+// archive. Every JSON, JSONC, YAML and INI entry is parsed and merged in
+// sorted path order, so later paths override earlier ones. Other entries
+// are skipped.
+type ArchiveSource struct {
+	Path    string
+	options *fileOptions
+}
+
+func (as *ArchiveSource) Priority() int { return 1 } // Same as files
+
+func (as *ArchiveSource) Load() (map[string]interface{}, error) {
+	var entries map[string][]byte
+	var err error
+	switch name := strings.ToLower(as.Path); {
+	case strings.HasSuffix(name, ".zip"):
+		entries, err = readZip(as.Path)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		entries, err = readTar(as.Path, true)
+	case strings.HasSuffix(name, ".tar"):
+		entries, err = readTar(as.Path, false)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", as.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", as.Path, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]interface{})
+	for _, name := range names {
+		format := as.options.format(strings.TrimPrefix(path.Ext(name), "."))
+		if !isConfigFormat(format) {
+			continue
+		}
+
+		data := entries[name]
+		if as.options != nil && as.options.template {
+			if data, err = renderTemplate(data, as.Path+":"+name); err != nil {
+				return nil, err
+			}
+		}
+		if as.options != nil && as.options.strict {
+			if err := checkStrictDecode(data, format); err != nil {
+				return nil, fmt.Errorf("failed to parse %s:%s: %w", as.Path, name, err)
+			}
+		}
+		parsed, err := parseData(data, format, as.Path+":"+name)
+		if err != nil {
+			return nil, err
+		}
+		mergeMaps(result, parsed)
+	}
+	return result, nil
+}
+
+// isConfigFormat reports whether parseData understands format
+func isConfigFormat(format string) bool {
+	switch format {
+	case "json", "jsonc", "yaml", "yml", "ini":
+		return true
+	}
+	return false
+}
+
+func readZip(name string) (map[string][]byte, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entries := make(map[string][]byte)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		entries[f.Name] = data
+	}
+	return entries, nil
+}
+
+func readTar(name string, gzipped bool) (map[string][]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		entries[header.Name] = data
+	}
+}
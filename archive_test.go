@@ -0,0 +1,84 @@
+package configflow
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveConfig struct {
+	Port int    `cfg:"port"`
+	Host string `cfg:"host"`
+	Mode string `cfg:"mode"`
+}
+
+var archiveFragments = []struct {
+	name string
+	body string
+}{
+	{"conf.d/20-override.json", `{"port": 9090, "mode": "prod"}`},
+	{"conf.d/10-base.yaml", "port: 8080\nhost: localhost\nmode: dev\n"},
+	{"README.md", "not config"},
+}
+
+func TestAddArchiveZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveFragments {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", f.name, err)
+		}
+		w.Write([]byte(f.body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to build zip: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+
+	config := &archiveConfig{}
+	if err := New().AddArchive(path).Load(config); err != nil {
+		t.Fatalf("Failed to load archive: %v", err)
+	}
+
+	// 20-override.json sorts after 10-base.yaml and wins
+	if config.Port != 9090 || config.Host != "localhost" || config.Mode != "prod" {
+		t.Errorf("Unexpected merged config: %+v", config)
+	}
+}
+
+func TestAddArchiveTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range archiveFragments {
+		header := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to add %s: %v", f.name, err)
+		}
+		tw.Write([]byte(f.body))
+	}
+	tw.Close()
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "config.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	config := &archiveConfig{}
+	if err := New().AddArchive(path).Load(config); err != nil {
+		t.Fatalf("Failed to load archive: %v", err)
+	}
+	if config.Port != 9090 || config.Host != "localhost" {
+		t.Errorf("Unexpected merged config: %+v", config)
+	}
+}
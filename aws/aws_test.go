@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) GetSecretString(ctx context.Context, id string) (string, error) {
+	value, ok := f[id]
+	if !ok {
+		return "", errors.New("ResourceNotFoundException")
+	}
+	return value, nil
+}
+
+type fakeParameters map[string]string
+
+func (f fakeParameters) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+	return f, nil
+}
+
+type awsConfig struct {
+	Port     int    `cfg:"port"`
+	URL      string `cfg:"database.url"`
+	Password string `cfg:"database.password,secret"`
+}
+
+func TestSecretAndParameterSources(t *testing.T) {
+	secrets := fakeSecrets{"prod/db": `{"password": "s3cret"}`}
+	params := fakeParameters{
+		"/app/prod/port":         "9090",
+		"/app/prod/database/url": "postgres://ssm/db",
+	}
+
+	config := &awsConfig{}
+	err := configflow.New().
+		AddMap(map[string]interface{}{"port": 8080}).
+		AddSource(NewParameterSource(params, "/app/prod")).
+		AddSource(NewSecretSource(secrets, "prod/db", "database")).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 9090 || config.URL != "postgres://ssm/db" || config.Password != "s3cret" {
+		t.Errorf("Unexpected config from AWS sources: %+v", config)
+	}
+}
+
+func TestSecretSourceErrors(t *testing.T) {
+	secrets := fakeSecrets{"plain": "not json"}
+
+	if _, err := NewSecretSource(secrets, "missing", "").Load(); err == nil {
+		t.Error("Expected error for a missing secret")
+	}
+	if _, err := NewSecretSource(secrets, "plain", "").Load(); err == nil {
+		t.Error("Expected error for a secret that is not a JSON object")
+	}
+}
@@ -0,0 +1,73 @@
+package configflow
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CgroupSource exposes the CPU and memory limits of the current container
+// as runtime.cpu_limit (in CPUs, e.g. 1.5) and runtime.mem_limit_bytes.
+// It reads cgroup v2 files under Root, falling back to the v1 layout.
+// Limits that are missing or unlimited are simply absent.
+type CgroupSource struct {
+	Root string // usually /sys/fs/cgroup
+}
+
+func (cs *CgroupSource) Priority() int { return 0 } // Same as maps
+
+func (cs *CgroupSource) Load() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	if cpu, ok := cs.cpuLimit(); ok {
+		result["runtime.cpu_limit"] = cpu
+	}
+	if mem, ok := cs.memLimit(); ok {
+		result["runtime.mem_limit_bytes"] = mem
+	}
+
+	return result, nil
+}
+
+func (cs *CgroupSource) cpuLimit() (float64, bool) {
+	// v2: "<quota> <period>" or "max <period>"
+	if fields := strings.Fields(cs.read("cpu.max")); len(fields) == 2 {
+		return cpuQuota(fields[0], fields[1])
+	}
+	// v1: quota is -1 when unlimited
+	return cpuQuota(cs.read("cpu/cpu.cfs_quota_us"), cs.read("cpu/cpu.cfs_period_us"))
+}
+
+func cpuQuota(quota, period string) (float64, bool) {
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+func (cs *CgroupSource) memLimit() (int64, bool) {
+	raw := cs.read("memory.max")
+	if raw == "" {
+		raw = cs.read("memory/memory.limit_in_bytes")
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	// v1 reports "unlimited" as a huge page-aligned number
+	if err != nil || n <= 0 || n >= 1<<62 {
+		return 0, false
+	}
+	return n, true
+}
+
+// read returns the trimmed content of a file below Root, or "" if it
+// cannot be read
+func (cs *CgroupSource) read(name string) string {
+	data, err := os.ReadFile(filepath.Join(cs.Root, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
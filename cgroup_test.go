@@ -0,0 +1,67 @@
+package configflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCgroupSource(t *testing.T) {
+	type Config struct {
+		CPULimit float64 `cfg:"runtime.cpu_limit"`
+		MemLimit int64   `cfg:"runtime.mem_limit_bytes"`
+	}
+
+	root := t.TempDir()
+	files := map[string]string{
+		"cpu.max":    "150000 100000\n",
+		"memory.max": "536870912\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{}
+	if err := New().AddSource(&CgroupSource{Root: root}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.CPULimit != 1.5 {
+		t.Errorf("Expected cpu limit 1.5, got %v", config.CPULimit)
+	}
+	if config.MemLimit != 512<<20 {
+		t.Errorf("Expected memory limit 512MiB, got %d", config.MemLimit)
+	}
+
+	// Unlimited and missing values contribute no keys
+	os.WriteFile(filepath.Join(root, "cpu.max"), []byte("max 100000\n"), 0644)
+	os.Remove(filepath.Join(root, "memory.max"))
+
+	data, err := (&CgroupSource{Root: root}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load cgroup limits: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected no keys for unlimited cgroup, got %v", data)
+	}
+}
+
+func TestAddCgroupLimitsIsLowestPriority(t *testing.T) {
+	loader := New().AddMap(map[string]interface{}{"runtime": map[string]interface{}{"cpu_limit": 2}}).AddCgroupLimits()
+
+	if _, ok := loader.sources[0].(*CgroupSource); !ok {
+		t.Fatalf("Expected cgroup source to be merged first, got %T", loader.sources[0])
+	}
+
+	type Config struct {
+		CPULimit float64 `cfg:"runtime.cpu_limit"`
+	}
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.CPULimit != 2 {
+		t.Errorf("Expected earlier source to override cgroup limit, got %v", config.CPULimit)
+	}
+}
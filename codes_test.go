@@ -0,0 +1,40 @@
+package configflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeValidators(t *testing.T) {
+	type Config struct {
+		Currency string `cfg:"currency" validate:"currency"`
+		Country  string `cfg:"country" validate:"countrycode"`
+	}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{"currency": "usd", "country": "De"}).Load(config)
+	if err != nil {
+		t.Fatalf("Expected valid codes to pass, got: %v", err)
+	}
+	if config.Currency != "usd" || config.Country != "De" {
+		t.Errorf("Expected values to load unchanged, got %+v", config)
+	}
+
+	tests := []struct {
+		data map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"currency": "USX"}, "ISO 4217"},
+		{map[string]interface{}{"country": "XY"}, "ISO 3166-1"},
+	}
+	for _, test := range tests {
+		err := New().AddMap(test.data).Load(&Config{})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: expected %s error, got: %v", test.data, test.want, err)
+		}
+	}
+
+	if len(countryCodes) != 249 {
+		t.Errorf("Expected 249 country codes, got %d", len(countryCodes))
+	}
+}
@@ -1,470 +1,3542 @@
-// Package configflow provides a flexible configuration management system
-// with validation, environment variable support, and multiple source loading.
-//
-// ConfigFlow is inspired by popular configuration libraries but designed
-// specifically for Go's type system and conventions.
-//
-// Features:
-//   - Load from multiple sources (files, environment variables, maps)
-//   - Built-in validation with custom validators
-//   - Support for JSON and YAML files
-//   - Environment variable override
-//   - Default values
-//   - Type conversion
-//   - Nested configuration support
-//
-// Example usage:
-//   type AppConfig struct {
-//       Port     int    `cfg:"port" env:"PORT" validate:"range:1000,9999"`
-//       Database string `cfg:"database.url" env:"DATABASE_URL" validate:"required,url"`
-//       Debug    bool   `cfg:"debug" env:"DEBUG" default:"false"`
-//   }
-//
-//   config := &AppConfig{}
-//   loader := configflow.New().
-//       AddFile("config.yaml").
-//       AddEnv().
-//       EnableValidation()
-//   
-//   err := loader.Load(config)
-package configflow
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/url"
-	"os"
-	"reflect"
-	"regexp"
-	"strconv"
-	"strings"
-
-	"gopkg.in/yaml.v3"
-)
-
-// Loader handles configuration loading from multiple sources
-type Loader struct {
-	sources    []Source
-	validators map[string]ValidatorFunc
-	strict     bool
-}
-
-// Source represents a configuration source
-type Source interface {
-	Load() (map[string]interface{}, error)
-	Priority() int
-}
-
-// ValidatorFunc validates a field value
-type ValidatorFunc func(value interface{}, param string) error
-
-// ValidationError represents a validation error
-type ValidationError struct {
-	Field   string
-	Value   interface{}
-	Rule    string
-	Message string
-}
-
-func (e ValidationError) Error() string {
-	return fmt.Sprintf("validation failed for field '%s': %s", e.Field, e.Message)
-}
-
-// New creates a new configuration loader
-func New() *Loader {
-	return &Loader{
-		sources:    make([]Source, 0),
-		validators: getBuiltinValidators(),
-		strict:     false,
-	}
-}
-
-// AddFile adds a file source (JSON, YAML, or TOML)
-func (l *Loader) AddFile(path string) *Loader {
-	l.sources = append(l.sources, &FileSource{Path: path})
-	return l
-}
-
-// AddEnv adds environment variables as a source
-func (l *Loader) AddEnv() *Loader {
-	l.sources = append(l.sources, &EnvSource{})
-	return l
-}
-
-// AddMap adds a map source (useful for defaults or testing)
-func (l *Loader) AddMap(data map[string]interface{}) *Loader {
-	l.sources = append(l.sources, &MapSource{Data: data})
-	return l
-}
-
-// EnableValidation enables field validation
-func (l *Loader) EnableValidation() *Loader {
-	// Validation is enabled by checking for validate tags
-	return l
-}
-
-// Strict enables strict mode (fail on unknown fields)
-func (l *Loader) Strict() *Loader {
-	l.strict = true
-	return l
-}
-
-// AddValidator adds a custom validator
-func (l *Loader) AddValidator(name string, validator ValidatorFunc) *Loader {
-	l.validators[name] = validator
-	return l
-}
-
-// Load loads configuration into the provided struct
-func (l *Loader) Load(config interface{}) error {
-	// Merge data from all sources
-	merged := make(map[string]interface{})
-	
-	// Sort sources by priority (higher priority overwrites lower)
-	for _, source := range l.sources {
-		data, err := source.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load from source: %w", err)
-		}
-		mergeMaps(merged, data)
-	}
-
-	// Apply to struct
-	return l.applyToStruct(config, merged)
-}
-
-// FileSource loads configuration from files
-type FileSource struct {
-	Path string
-}
-
-func (fs *FileSource) Priority() int { return 1 }
-
-func (fs *FileSource) Load() (map[string]interface{}, error) {
-	data, err := os.ReadFile(fs.Path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return make(map[string]interface{}), nil // File doesn't exist, return empty
-		}
-		return nil, err
-	}
-
-	var result map[string]interface{}
-	
-	// Determine format by extension
-	ext := strings.ToLower(fs.Path[strings.LastIndex(fs.Path, ".")+1:])
-	switch ext {
-	case "json":
-		err = json.Unmarshal(data, &result)
-	case "yaml", "yml":
-		err = yaml.Unmarshal(data, &result)
-	default:
-		return nil, fmt.Errorf("unsupported file format: %s", ext)
-	}
-	
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse %s: %w", fs.Path, err)
-	}
-	
-	return flattenMap(result, ""), nil
-}
-
-// EnvSource loads configuration from environment variables
-type EnvSource struct{}
-
-func (es *EnvSource) Priority() int { return 2 } // Higher priority than files
-
-func (es *EnvSource) Load() (map[string]interface{}, error) {
-	result := make(map[string]interface{})
-	
-	for _, env := range os.Environ() {
-		parts := strings.SplitN(env, "=", 2)
-		if len(parts) == 2 {
-			key := strings.ToLower(parts[0])
-			value := parts[1]
-			
-			// Try to parse as different types
-			if parsed := parseValue(value); parsed != nil {
-				result[key] = parsed
-			} else {
-				result[key] = value
-			}
-		}
-	}
-	
-	return result, nil
-}
-
-// MapSource loads from a map (useful for defaults)
-type MapSource struct {
-	Data map[string]interface{}
-}
-
-func (ms *MapSource) Priority() int { return 0 } // Lowest priority
-
-func (ms *MapSource) Load() (map[string]interface{}, error) {
-	return flattenMap(ms.Data, ""), nil
-}
-
-// Helper functions
-
-func mergeMaps(dst, src map[string]interface{}) {
-	for k, v := range src {
-		dst[k] = v
-	}
-}
-
-func flattenMap(m map[string]interface{}, prefix string) map[string]interface{} {
-	result := make(map[string]interface{})
-	
-	for k, v := range m {
-		key := k
-		if prefix != "" {
-			key = prefix + "." + k
-		}
-		
-		if nested, ok := v.(map[string]interface{}); ok {
-			for nk, nv := range flattenMap(nested, key) {
-				result[nk] = nv
-			}
-		} else {
-			result[key] = v
-		}
-	}
-	
-	return result
-}
-
-func parseValue(s string) interface{} {
-	// Try boolean
-	if b, err := strconv.ParseBool(s); err == nil {
-		return b
-	}
-	
-	// Try int
-	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-		return i
-	}
-	
-	// Try float
-	if f, err := strconv.ParseFloat(s, 64); err == nil {
-		return f
-	}
-	
-	return s // Return as string
-}
-
-func (l *Loader) applyToStruct(config interface{}, data map[string]interface{}) error {
-	v := reflect.ValueOf(config)
-	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("config must be a pointer to struct")
-	}
-	
-	v = v.Elem()
-	t := v.Type()
-	
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		fieldType := t.Field(i)
-		
-		if !field.CanSet() {
-			continue
-		}
-		
-		// Get field configuration
-		cfg := l.getFieldConfig(fieldType)
-		
-		// Find value from sources
-		value := l.findValue(data, cfg)
-		
-		if value != nil {
-			// Validate if needed
-			if cfg.validate != "" {
-				if err := l.validateField(fieldType.Name, value, cfg.validate); err != nil {
-					return err
-				}
-			}
-			
-			// Set value
-			if err := l.setValue(field, value); err != nil {
-				return fmt.Errorf("failed to set field %s: %w", fieldType.Name, err)
-			}
-		} else if cfg.defaultValue != "" {
-			// Use default value
-			parsed := parseValue(cfg.defaultValue)
-			if err := l.setValue(field, parsed); err != nil {
-				return fmt.Errorf("failed to set default for field %s: %w", fieldType.Name, err)
-			}
-		}
-	}
-	
-	return nil
-}
-
-type fieldConfig struct {
-	cfgKey       string
-	envKey       string
-	validate     string
-	defaultValue string
-}
-
-func (l *Loader) getFieldConfig(field reflect.StructField) fieldConfig {
-	return fieldConfig{
-		cfgKey:       field.Tag.Get("cfg"),
-		envKey:       field.Tag.Get("env"),
-		validate:     field.Tag.Get("validate"),
-		defaultValue: field.Tag.Get("default"),
-	}
-}
-
-func (l *Loader) findValue(data map[string]interface{}, cfg fieldConfig) interface{} {
-	// Check environment key first (higher priority)
-	if cfg.envKey != "" {
-		if value, ok := data[strings.ToLower(cfg.envKey)]; ok {
-			return value
-		}
-	}
-	
-	// Check config key
-	if cfg.cfgKey != "" {
-		if value, ok := data[cfg.cfgKey]; ok {
-			return value
-		}
-	}
-	
-	return nil
-}
-
-func (l *Loader) setValue(field reflect.Value, value interface{}) error {
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString(fmt.Sprintf("%v", value))
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if i, err := strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64); err == nil {
-			field.SetInt(i)
-		} else {
-			return err
-		}
-	case reflect.Bool:
-		if b, err := strconv.ParseBool(fmt.Sprintf("%v", value)); err == nil {
-			field.SetBool(b)
-		} else {
-			return err
-		}
-	case reflect.Float32, reflect.Float64:
-		if f, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64); err == nil {
-			field.SetFloat(f)
-		} else {
-			return err
-		}
-	}
-	
-	return nil
-}
-
-func (l *Loader) validateField(fieldName string, value interface{}, rules string) error {
-	for _, rule := range strings.Split(rules, ",") {
-		rule = strings.TrimSpace(rule)
-		
-		parts := strings.SplitN(rule, ":", 2)
-		ruleName := parts[0]
-		param := ""
-		if len(parts) > 1 {
-			param = parts[1]
-		}
-		
-		if validator, ok := l.validators[ruleName]; ok {
-			if err := validator(value, param); err != nil {
-				return &ValidationError{
-					Field:   fieldName,
-					Value:   value,
-					Rule:    rule,
-					Message: err.Error(),
-				}
-			}
-		}
-	}
-	
-	return nil
-}
-
-// Built-in validators
-func getBuiltinValidators() map[string]ValidatorFunc {
-	return map[string]ValidatorFunc{
-		"required": func(value interface{}, param string) error {
-			if value == nil || fmt.Sprintf("%v", value) == "" {
-				return fmt.Errorf("field is required")
-			}
-			return nil
-		},
-		"url": func(value interface{}, param string) error {
-			str := fmt.Sprintf("%v", value)
-			if _, err := url.Parse(str); err != nil {
-				return fmt.Errorf("invalid URL format")
-			}
-			return nil
-		},
-		"range": func(value interface{}, param string) error {
-			parts := strings.Split(param, ",")
-			if len(parts) != 2 {
-				return fmt.Errorf("range validator requires min,max parameters")
-			}
-			
-			min, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
-			max, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
-			if err1 != nil || err2 != nil {
-				return fmt.Errorf("range parameters must be integers")
-			}
-			
-			val, err := strconv.Atoi(fmt.Sprintf("%v", value))
-			if err != nil {
-				return fmt.Errorf("value must be an integer for range validation")
-			}
-			
-			if val < min || val > max {
-				return fmt.Errorf("value must be between %d and %d", min, max)
-			}
-			return nil
-		},
-		"email": func(value interface{}, param string) error {
-			str := fmt.Sprintf("%v", value)
-			emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-			if !emailRegex.MatchString(str) {
-				return fmt.Errorf("invalid email format")
-			}
-			return nil
-		},
-		"min": func(value interface{}, param string) error {
-			minVal, err := strconv.Atoi(param)
-			if err != nil {
-				return fmt.Errorf("min parameter must be an integer")
-			}
-			
-			val, err := strconv.Atoi(fmt.Sprintf("%v", value))
-			if err != nil {
-				return fmt.Errorf("value must be an integer for min validation")
-			}
-			
-			if val < minVal {
-				return fmt.Errorf("value must be at least %d", minVal)
-			}
-			return nil
-		},
-		"max": func(value interface{}, param string) error {
-			maxVal, err := strconv.Atoi(param)
-			if err != nil {
-				return fmt.Errorf("max parameter must be an integer")
-			}
-			
-			val, err := strconv.Atoi(fmt.Sprintf("%v", value))
-			if err != nil {
-				return fmt.Errorf("value must be an integer for max validation")
-			}
-			
-			if val > maxVal {
-				return fmt.Errorf("value must be at most %d", maxVal)
-			}
-			return nil
-		},
-	}
-}
\ No newline at end of file
+// Package configflow provides a flexible configuration management system
+// with validation, environment variable support, and multiple source loading.
+//
+// ConfigFlow is inspired by popular configuration libraries but designed
+// specifically for Go's type system and conventions.
+//
+// Features:
+//   - Load from multiple sources (files, environment variables, maps)
+//   - Built-in validation with custom validators
+//   - Support for JSON, JSONC, YAML and INI files
+//   - Environment variable override
+//   - Default values
+//   - Type conversion
+//   - Nested configuration support
+//
+// Example usage:
+//
+//	type AppConfig struct {
+//	    Port     int    `cfg:"port" env:"PORT" validate:"range:1000,9999"`
+//	    Database string `cfg:"database.url" env:"DATABASE_URL" validate:"required,url"`
+//	    Debug    bool   `cfg:"debug" env:"DEBUG" default:"false"`
+//	}
+//
+//	config := &AppConfig{}
+//	loader := configflow.New().
+//	    AddFile("config.yaml").
+//	    AddEnv().
+//	    EnableValidation()
+//
+//	err := loader.Load(config)
+package configflow
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Loader handles configuration loading from multiple sources
+type Loader struct {
+	sources           []Source
+	validators        map[string]ValidatorFunc
+	contextual        map[string]contextValidatorFunc
+	transforms        map[string]TransformValidator
+	strict            bool
+	strictTypes       bool
+	parallel          bool
+	verbose           bool
+	collect           bool
+	requireTags       bool
+	mergeSlices       bool
+	noDefaults        bool
+	interpolate       bool
+	continueOnError   bool
+	jsonFallback      bool
+	strictValidators  bool
+	emptyEnvTrue      bool
+	floatFormat       byte // strconv.FormatFloat format for string fields, 0 for %v
+	floatPrec         int
+	allRules          bool
+	enums             map[reflect.Type]map[string]int64
+	setupErrs         []error // invalid builder calls, reported by Load
+	variants          map[string]map[string]reflect.Type
+	envBindings       map[string]string
+	setArgs           []string
+	keySeparators     []string // rewritten to dots by NormalizeKeys
+	filesOverrideEnv  bool
+	groups            []group // structs loaded under a prefix by Group
+	defaultFuncs      map[string]func() interface{}
+	fieldParsers      map[string]func(raw interface{}) (interface{}, error)
+	values            map[string]interface{}   // merged values of the last load
+	sourceErrors      []error                  // sources skipped by the last load
+	warnings          ValidationErrors         // warn: rule failures of the last load
+	lastResults       []map[string]interface{} // data of each source from the last load
+	refresh           func(Source) bool        // sources re-read by ReloadSources
+	scope             string                   // key prefix applied by Scope
+	onUnknown         func(key string, value interface{})
+	onValidationError func(ve ValidationError) error
+	preLoad           []func() error
+	postLoad          []func(config interface{}) error
+	env               envOptions
+	files             fileOptions
+}
+
+// Source represents a configuration source
+type Source interface {
+	Load() (map[string]interface{}, error)
+	Priority() int
+}
+
+// ContextSource is a Source that can be cancelled through a context,
+// typically because it fetches data over the network
+type ContextSource interface {
+	Source
+	LoadContext(ctx context.Context) (map[string]interface{}, error)
+}
+
+// ValidatorFunc validates a field value
+type ValidatorFunc func(value interface{}, param string) error
+
+// TransformValidator is a validator that can also normalize the value
+// (e.g. trim or lowercase it). The returned value replaces the original
+// for the remaining rules and is the one set on the field.
+type TransformValidator interface {
+	Transform(value interface{}) (interface{}, error)
+}
+
+// TransformFunc adapts a function to the TransformValidator interface
+type TransformFunc func(value interface{}) (interface{}, error)
+
+// Transform calls f(value)
+func (f TransformFunc) Transform(value interface{}) (interface{}, error) {
+	return f(value)
+}
+
+// contextValidatorFunc validates a field value with access to the field
+// being loaded and the merged configuration data
+type contextValidatorFunc func(ctx fieldContext, value interface{}, param string) error
+
+// fieldContext describes the field a validator is running against
+type fieldContext struct {
+	name   string
+	field  reflect.Value
+	parent reflect.Value
+	data   map[string]interface{}
+	secret bool
+}
+
+// ValidationError represents a validation error
+type ValidationError struct {
+	Field   string
+	Value   interface{}
+	Rule    string
+	Message string
+
+	verbose bool // include rule and value in Error()
+	secret  bool // mask the value in Error()
+}
+
+func (e ValidationError) Error() string {
+	if e.verbose {
+		value := fmt.Sprintf("%v", e.Value)
+		if e.secret {
+			value = maskedValue
+		}
+		return fmt.Sprintf("validation failed for field '%s' (rule '%s', value '%s'): %s",
+			e.Field, e.Rule, value, e.Message)
+	}
+	return fmt.Sprintf("validation failed for field '%s': %s", e.Field, e.Message)
+}
+
+// ValidationErrors holds every validation failure of a load in collect mode
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap exposes the individual failures to errors.Is and errors.As
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// maskedValue replaces the value of secret fields in messages
+const maskedValue = "******"
+
+// UnsupportedKindError is returned in strict types mode when a field's
+// kind cannot be set from configuration
+type UnsupportedKindError struct {
+	Field string
+	Kind  reflect.Kind
+}
+
+func (e *UnsupportedKindError) Error() string {
+	return fmt.Sprintf("field '%s' has unsupported kind %s", e.Field, e.Kind)
+}
+
+// LossyConversionError is returned in strict types mode when a value
+// would lose precision, e.g. a float with a fractional part set on an
+// integer field
+type LossyConversionError struct {
+	Field string
+	Value interface{}
+	Kind  reflect.Kind
+}
+
+func (e *LossyConversionError) Error() string {
+	return fmt.Sprintf("field '%s': converting %v to %s loses precision", e.Field, e.Value, e.Kind)
+}
+
+// ConversionError is returned when a value taken from an environment
+// variable cannot be converted to the field's type
+type ConversionError struct {
+	Variable string
+	Field    string
+	Type     reflect.Type
+	Value    interface{}
+	Err      error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("environment variable %s=%q cannot be converted to %s for field '%s': %v",
+		e.Variable, fmt.Sprintf("%v", e.Value), e.Type, e.Field, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error { return e.Err }
+
+// New creates a new configuration loader
+func New() *Loader {
+	return &Loader{
+		sources:    make([]Source, 0),
+		validators: getBuiltinValidators(),
+		contextual: getContextValidators(),
+		strict:     false,
+	}
+}
+
+// AddFile adds a file source (JSON, JSONC, YAML, or INI)
+func (l *Loader) AddFile(path string) *Loader {
+	l.sources = append(l.sources, &FileSource{Path: path, options: &l.files})
+	return l
+}
+
+// AddFileAs adds a file source parsed in the given format regardless of
+// its extension, e.g. AddFileAs("app.conf", "yaml")
+func (l *Loader) AddFileAs(path, format string) *Loader {
+	l.sources = append(l.sources, &FileSource{Path: path, Format: format, options: &l.files})
+	return l
+}
+
+// AddFileLayers adds files merged in the listed order as a single source,
+// so each file overrides the ones before it. Missing files are skipped like
+// with AddFile.
+func (l *Loader) AddFileLayers(paths ...string) *Loader {
+	l.sources = append(l.sources, &FileLayersSource{Paths: paths, options: &l.files})
+	return l
+}
+
+// AddArchive adds a zip, tar or tar.gz archive of config fragments
+func (l *Loader) AddArchive(path string) *Loader {
+	l.sources = append(l.sources, &ArchiveSource{Path: path, options: &l.files})
+	return l
+}
+
+// AddProfileFiles adds baseDir/baseName and, when the profileEnv variable
+// is set, the profile overlay on top of it (config.yaml is overlaid by
+// config.<profile>.yaml). A missing profile file is not an error.
+func (l *Loader) AddProfileFiles(baseDir, baseName, profileEnv string) *Loader {
+	l.AddFile(filepath.Join(baseDir, baseName))
+
+	if profile := os.Getenv(profileEnv); profile != "" {
+		ext := filepath.Ext(baseName)
+		name := strings.TrimSuffix(baseName, ext) + "." + profile + ext
+		l.AddFile(filepath.Join(baseDir, name))
+	}
+	return l
+}
+
+// hostname is replaced in tests
+var hostname = os.Hostname
+
+// AddHostOverride adds dir/baseName and the overlay for the running host
+// on top of it (config.yaml is overlaid by config.<hostname>.yaml). A
+// missing host file is not an error.
+func (l *Loader) AddHostOverride(dir, baseName string) *Loader {
+	l.AddFile(filepath.Join(dir, baseName))
+
+	if host, err := hostname(); err == nil && host != "" {
+		ext := filepath.Ext(baseName)
+		name := strings.TrimSuffix(baseName, ext) + "." + host + ext
+		l.AddFile(filepath.Join(dir, name))
+	}
+	return l
+}
+
+// AddXDGConfig adds appName/config.yaml from the user's config directory:
+// $XDG_CONFIG_HOME when set, otherwise the platform default (~/.config on
+// Linux, ~/Library/Application Support on macOS). A missing directory or
+// file is not an error.
+func (l *Loader) AddXDGConfig(appName string) *Loader {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserConfigDir(); err != nil {
+			return l
+		}
+	}
+	return l.AddFile(filepath.Join(dir, appName, "config.yaml"))
+}
+
+// AddReader adds a source read from r in the given format (json, jsonc, yaml)
+func (l *Loader) AddReader(r io.Reader, format string) *Loader {
+	l.sources = append(l.sources, &ReaderSource{Reader: r, Format: format})
+	return l
+}
+
+// AddRemoteFunc adds a source fetched by fn, which returns raw bytes and
+// their format (json, jsonc, yaml, ini)
+func (l *Loader) AddRemoteFunc(fn func(ctx context.Context) ([]byte, string, error)) *Loader {
+	l.sources = append(l.sources, &RemoteFuncSource{Fetch: fn})
+	return l
+}
+
+// AddYAML adds an inline YAML document as a source
+func (l *Loader) AddYAML(s string) *Loader {
+	return l.AddReader(strings.NewReader(s), "yaml")
+}
+
+// AddJSON adds an inline JSON document as a source
+func (l *Loader) AddJSON(s string) *Loader {
+	return l.AddReader(strings.NewReader(s), "json")
+}
+
+// AddEnv adds environment variables as a source
+func (l *Loader) AddEnv() *Loader {
+	l.sources = append(l.sources, &EnvSource{options: &l.env})
+	return l
+}
+
+// AddCgroupLimits adds the container's CPU and memory limits from
+// /sys/fs/cgroup as runtime.cpu_limit and runtime.mem_limit_bytes. The
+// source is placed before all others, wherever it is called, so every
+// other source overrides it.
+func (l *Loader) AddCgroupLimits() *Loader {
+	l.sources = append([]Source{&CgroupSource{Root: "/sys/fs/cgroup"}}, l.sources...)
+	return l
+}
+
+// AddMap adds a map source (useful for defaults or testing)
+func (l *Loader) AddMap(data map[string]interface{}) *Loader {
+	l.sources = append(l.sources, &MapSource{Data: data})
+	return l
+}
+
+// AddResolvedMap adds a map whose keys are already dotted paths, such as the
+// settings of a viper instance. Its keys are used as-is rather than being
+// flattened again.
+func (l *Loader) AddResolvedMap(data map[string]interface{}) *Loader {
+	l.sources = append(l.sources, &ResolvedMapSource{Data: data})
+	return l
+}
+
+// AddDB adds the rows of a SQL query returning (key, value) columns
+func (l *Loader) AddDB(db *sql.DB, query string, args ...interface{}) *Loader {
+	l.sources = append(l.sources, &DBSource{DB: db, Query: query, Args: args})
+	return l
+}
+
+// AddSetArgs adds "key=value" overrides, such as those collected from
+// repeated --set flags. Keys are dotted paths and values are parsed like
+// environment variables. They take precedence over every source.
+func (l *Loader) AddSetArgs(pairs []string) *Loader {
+	l.setArgs = append(l.setArgs, pairs...)
+	return l
+}
+
+// AddSource adds a custom source
+func (l *Loader) AddSource(source Source) *Loader {
+	l.sources = append(l.sources, source)
+	return l
+}
+
+// EnableValidation enables field validation
+func (l *Loader) EnableValidation() *Loader {
+	// Validation is enabled by checking for validate tags
+	return l
+}
+
+// RequireTags makes Load fail when a settable field has no cfg tag, to
+// catch fields that accidentally can't be configured. Tag a field with
+// `cfg:"-"` to exclude it on purpose.
+func (l *Loader) RequireTags() *Loader {
+	l.requireTags = true
+	return l
+}
+
+// NoDefaults disables default, default-json and DefaultFunc values, so
+// fields not provided by any source stay at their zero value.
+func (l *Loader) NoDefaults() *Loader {
+	l.noDefaults = true
+	return l
+}
+
+// MergeSlices makes list values from every source combine instead of the
+// highest priority source replacing the rest. Items are appended in source
+// priority order and duplicates are dropped. Use the merge option
+// (`cfg:"plugins,merge"`) to do this for individual fields only.
+func (l *Loader) MergeSlices() *Loader {
+	l.mergeSlices = true
+	return l
+}
+
+// UseJSONTagsFallback keys fields without a cfg tag by the name in their
+// json tag, so structs annotated for encoding/json load as-is. A cfg tag
+// always wins.
+func (l *Loader) UseJSONTagsFallback() *Loader {
+	l.jsonFallback = true
+	return l
+}
+
+// StrictValidators makes Load fail when a validate tag names a rule that
+// has no registered validator, instead of silently skipping it
+func (l *Loader) StrictValidators() *Loader {
+	l.strictValidators = true
+	return l
+}
+
+// FloatStringFormat sets how float values are formatted when loaded into
+// string fields, using strconv.FormatFloat's format and precision. By
+// default they are formatted with %v, so 1e6 becomes "1e+06";
+// FloatStringFormat('f', -1) gives "1000000" instead.
+func (l *Loader) FloatStringFormat(format byte, prec int) *Loader {
+	l.floatFormat = format
+	l.floatPrec = prec
+	return l
+}
+
+// EmptyEnvAsTrue treats an environment variable that is set but empty as
+// true for bool fields, so exporting DEBUG= enables a Debug flag
+func (l *Loader) EmptyEnvAsTrue() *Loader {
+	l.emptyEnvTrue = true
+	return l
+}
+
+// FilesOverrideEnv gives file sources precedence over environment
+// variables, e.g. when a sealed config file is authoritative. Sources are
+// otherwise still merged in the order they were added; only env sources
+// move below the first file source. Only AddFile, AddFileLayers,
+// AddArchive, AddReader and the inline AddYAML and AddJSON sources count as
+// files; remote sources such as etcd, Consul, databases and AddRemoteFunc
+// do not. BindEnv and AddSetArgs still override everything.
+func (l *Loader) FilesOverrideEnv() *Loader {
+	l.filesOverrideEnv = true
+	return l
+}
+
+// NormalizeKeys rewrites the given separators (underscores by default) to
+// dots in every source key and cfg tag, so DATABASE_URL from the
+// environment and database.url from a file are the same key and override
+// each other in source order
+func (l *Loader) NormalizeKeys(separators ...string) *Loader {
+	if len(separators) == 0 {
+		separators = []string{"_"}
+	}
+	l.keySeparators = separators
+	return l
+}
+
+// canonicalKey applies NormalizeKeys to key
+func (l *Loader) canonicalKey(key string) string {
+	for _, sep := range l.keySeparators {
+		key = strings.ReplaceAll(key, sep, ".")
+	}
+	return key
+}
+
+// canonicalKeys returns data with its keys passed through canonicalKey
+func (l *Loader) canonicalKeys(data map[string]interface{}) map[string]interface{} {
+	if len(l.keySeparators) == 0 {
+		return data
+	}
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[l.canonicalKey(k)] = v
+	}
+	return result
+}
+
+// Strict enables strict mode (fail on unknown fields). Keys from the
+// environment are not checked since it holds unrelated variables.
+func (l *Loader) Strict() *Loader {
+	l.strict = true
+	return l
+}
+
+// OnUnknownKey registers a callback invoked for every key that no field
+// declares. It composes with Strict: the callback fires first, then the
+// load fails if strict mode is on.
+func (l *Loader) OnUnknownKey(fn func(key string, value interface{})) *Loader {
+	l.onUnknown = fn
+	return l
+}
+
+// EnableInterpolation expands ${VAR} references to environment variables in
+// string values after all sources are merged. ${VAR:-fallback} substitutes
+// fallback when VAR is unset or empty and ${VAR:?message} fails the load.
+// Values that come from environment variables are not expanded again.
+func (l *Loader) EnableInterpolation() *Loader {
+	l.interpolate = true
+	return l
+}
+
+// EnableTemplating renders files with text/template before parsing them.
+// Templates can read the environment with {{ .Env "NAME" }} or
+// {{ env "NAME" }}, and {{ env "NAME" | default "x" }} supplies a fallback.
+func (l *Loader) EnableTemplating() *Loader {
+	l.files.template = true
+	return l
+}
+
+// StrictDecode makes file parsing reject duplicate JSON object keys and
+// YAML files with more than one document instead of silently picking one.
+// Unlike Strict, it checks the files themselves, not the struct's keys.
+func (l *Loader) StrictDecode() *Loader {
+	l.files.strict = true
+	return l
+}
+
+// EnableJSONC parses .json files as JSONC, allowing comments and trailing
+// commas. Files with a .jsonc extension are always parsed this way.
+func (l *Loader) EnableJSONC() *Loader {
+	l.files.jsonc = true
+	return l
+}
+
+// EnableFileSecrets resolves KEY_FILE environment variables by reading the
+// referenced file and using its contents as the value of KEY (Docker
+// secrets convention). A directly set KEY takes precedence over KEY_FILE.
+// Names are taken after MapEnv, and files that cannot be read are skipped.
+func (l *Loader) EnableFileSecrets() *Loader {
+	l.env.fileSecrets = true
+	return l
+}
+
+// EnvJSON marks environment variables whose values are JSON. An object is
+// flattened under the variable's lowercased name, so FEATURES='{"a":true}'
+// provides features.a; other JSON values are used as the value of the key.
+func (l *Loader) EnvJSON(names ...string) *Loader {
+	if l.env.jsonVars == nil {
+		l.env.jsonVars = make(map[string]bool)
+	}
+	for _, name := range names {
+		l.env.jsonVars[strings.ToUpper(name)] = true
+	}
+	return l
+}
+
+// MapEnv registers a function applied to every environment variable read
+// by env sources, before its value is parsed. It receives the variable's
+// name and value and returns the name (lowercased to form the key) and
+// value to use instead, or false to drop the variable.
+func (l *Loader) MapEnv(fn func(key, value string) (string, string, bool)) *Loader {
+	l.env.mapper = fn
+	return l
+}
+
+// OnValidationError registers a hook invoked for every failed rule. The
+// error it returns replaces the failure (e.g. with a localized message);
+// returning nil suppresses the failure.
+func (l *Loader) OnValidationError(fn func(ve ValidationError) error) *Loader {
+	l.onValidationError = fn
+	return l
+}
+
+// ContinueOnSourceError skips sources that fail to load so the others
+// still contribute. The load only fails if every source failed or a source
+// wrapped with Required failed. SourceErrors reports the skipped failures.
+func (l *Loader) ContinueOnSourceError() *Loader {
+	l.continueOnError = true
+	return l
+}
+
+// Warnings returns the failures of advisory rules, written with a warn:
+// prefix such as `validate:"warn:range:1,100"`, from the most recent load.
+// Such failures never fail the load.
+func (l *Loader) Warnings() ValidationErrors {
+	return l.warnings
+}
+
+// SourceErrors returns the errors of sources skipped by the most recent
+// load under ContinueOnSourceError
+func (l *Loader) SourceErrors() []error {
+	return l.sourceErrors
+}
+
+// PreLoad registers a hook that runs before any source is read, e.g. to
+// prepare the environment. An error aborts the load.
+func (l *Loader) PreLoad(fn func() error) *Loader {
+	l.preLoad = append(l.preLoad, fn)
+	return l
+}
+
+// RequireEnv adds a PreLoad hook that fails the load, before any source is
+// read, when any of the named environment variables is unset. The error
+// names every missing variable.
+func (l *Loader) RequireEnv(names ...string) *Loader {
+	return l.PreLoad(func() error {
+		var missing []string
+		for _, name := range names {
+			if _, ok := os.LookupEnv(name); !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+}
+
+// PostLoad registers a hook that runs once config has been populated and
+// its fields validated, e.g. to compute derived fields. It runs before the
+// config's own Validate method, and an error fails the load.
+func (l *Loader) PostLoad(fn func(config interface{}) error) *Loader {
+	l.postLoad = append(l.postLoad, fn)
+	return l
+}
+
+// CollectErrors makes Load validate every field and return all failures
+// as ValidationErrors instead of stopping at the first one
+func (l *Loader) CollectErrors() *Loader {
+	l.collect = true
+	return l
+}
+
+// ReportAllRules makes collect mode report every failing rule of a field
+// rather than only the first. It has no effect without CollectErrors.
+func (l *Loader) ReportAllRules() *Loader {
+	l.allRules = true
+	return l
+}
+
+// VerboseErrors includes the failing rule and value in validation error
+// messages. Values of fields tagged with the secret option are masked.
+func (l *Loader) VerboseErrors() *Loader {
+	l.verbose = true
+	return l
+}
+
+// StrictTypes makes loading fail on fields whose kind cannot be set
+// instead of silently leaving them at their zero value, and on
+// conversions that would lose precision
+func (l *Loader) StrictTypes() *Loader {
+	l.strictTypes = true
+	return l
+}
+
+// AddTransformValidator adds a validator that may replace the value
+// before it is set on the field
+func (l *Loader) AddTransformValidator(name string, validator TransformValidator) *Loader {
+	if l.transforms == nil {
+		l.transforms = make(map[string]TransformValidator)
+	}
+	l.transforms[name] = validator
+	return l
+}
+
+// RegisterVariant registers a concrete struct for a polymorphic section.
+// typeKey is the discriminator key (e.g. "storage.type"); when an
+// interface-typed field tagged with its parent key ("storage") is loaded
+// and the discriminator equals typeName, the section is decoded into a
+// new value of the prototype's type (pointer prototypes yield pointers).
+func (l *Loader) RegisterVariant(typeKey, typeName string, prototype interface{}) *Loader {
+	if l.variants == nil {
+		l.variants = make(map[string]map[string]reflect.Type)
+	}
+	if l.variants[typeKey] == nil {
+		l.variants[typeKey] = make(map[string]reflect.Type)
+	}
+	l.variants[typeKey][typeName] = reflect.TypeOf(prototype)
+	return l
+}
+
+// RegisterEnum maps names to values for a named integer type, so fields of
+// that type can be set from a name in config (e.g. "warn"). Registering a
+// non-integer type makes Load fail.
+func (l *Loader) RegisterEnum(t reflect.Type, values map[string]int64) *Loader {
+	if t == nil || (!isIntKind(t.Kind()) && !isUintKind(t.Kind())) {
+		l.setupErrs = append(l.setupErrs, fmt.Errorf("RegisterEnum: %v is not an integer type", t))
+		return l
+	}
+	if l.enums == nil {
+		l.enums = make(map[reflect.Type]map[string]int64)
+	}
+	l.enums[t] = values
+	return l
+}
+
+// AddValidator adds a custom validator
+func (l *Loader) AddValidator(name string, validator ValidatorFunc) *Loader {
+	l.validators[name] = validator
+	return l
+}
+
+// DefaultFunc registers a function computing the default for a key at
+// load time (e.g. the hostname). It is used only when no source provides
+// the key and the field has no static default tag.
+func (l *Loader) DefaultFunc(key string, fn func() interface{}) *Loader {
+	if l.defaultFuncs == nil {
+		l.defaultFuncs = make(map[string]func() interface{})
+	}
+	l.defaultFuncs[key] = fn
+	return l
+}
+
+// RegisterFieldParser converts the raw value of key with fn before it is
+// assigned, e.g. to turn a CSV string into a []int. The result then goes
+// through the usual conversion to the field's type.
+func (l *Loader) RegisterFieldParser(key string, fn func(raw interface{}) (interface{}, error)) *Loader {
+	if l.fieldParsers == nil {
+		l.fieldParsers = make(map[string]func(raw interface{}) (interface{}, error))
+	}
+	l.fieldParsers[key] = fn
+	return l
+}
+
+// BindEnv binds an environment variable to a config key, overriding any
+// source value for that key when the variable is set. Bindings work
+// without struct tags, e.g. with LoadMap and Get.
+func (l *Loader) BindEnv(key, envVar string) *Loader {
+	if l.envBindings == nil {
+		l.envBindings = make(map[string]string)
+	}
+	l.envBindings[key] = envVar
+	return l
+}
+
+// LoadMap loads all sources and returns the merged configuration as a
+// nested map, without applying it to a struct
+func (l *Loader) LoadMap() (map[string]interface{}, error) {
+	merged, err := l.loadSources(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	l.values = merged.values
+
+	result := make(map[string]interface{})
+	for key, value := range merged.values {
+		setPath(result, key, value)
+	}
+	return result, nil
+}
+
+// Get returns the value of a dotted key from the most recent Load or
+// LoadMap
+func (l *Loader) Get(key string) (interface{}, bool) {
+	value, ok := l.values[key]
+	return value, ok
+}
+
+// GetPath navigates the nested form of the most recent Load or LoadMap
+// and returns the value at path. Numeric segments index into lists, as in
+// "servers.0.host".
+func (l *Loader) GetPath(path string) (interface{}, error) {
+	root := make(map[string]interface{})
+	for key, value := range l.values {
+		setPath(root, key, value)
+	}
+	return lookupPath(root, path)
+}
+
+// ValidateKey runs validation rules, written as in a validate tag, against
+// the value of a dotted key from the most recent Load or LoadMap. Failures
+// are reported as a ValidationError whose Field is the key. Rules that
+// compare sibling fields cannot be used here.
+func (l *Loader) ValidateKey(key, rules string) error {
+	value, ok := l.values[key]
+	ctx := fieldContext{name: key, field: reflect.ValueOf(value), data: l.values}
+	if !ok || value == nil {
+		// A nil interface counts as empty for required
+		ctx.field = reflect.ValueOf(&value).Elem()
+		value = nil
+	}
+
+	if _, err := l.validateField(ctx, value, rules); err != nil {
+		return err
+	}
+	return l.validateDeferred(ctx, rules)
+}
+
+// Scope returns a view of the loader rooted at prefix: a field tagged
+// `cfg:"url"` loaded through Scope("database") reads "database.url".
+// The view shares sources, validators and options with l.
+func (l *Loader) Scope(prefix string) *Loader {
+	scoped := *l
+	if l.scope != "" {
+		prefix = l.scope + "." + prefix
+	}
+	scoped.scope = prefix
+	return &scoped
+}
+
+// group is a struct populated under a key prefix alongside the main config
+type group struct {
+	prefix string
+	target interface{}
+}
+
+// Group populates target, a pointer to struct, from the keys under prefix
+// during each Load, alongside the main config and from the same merged
+// sources. It is like loading target through Scope(prefix), without
+// reading the sources again:
+//
+//	loader.Group("database", &db).Group("cache", &cache).Load(&config)
+func (l *Loader) Group(prefix string, target interface{}) *Loader {
+	l.groups = append(l.groups, group{prefix: prefix, target: target})
+	return l
+}
+
+// ParallelSources makes Load fetch all sources concurrently. Results are
+// still merged in the order the sources were added.
+func (l *Loader) ParallelSources() *Loader {
+	l.parallel = true
+	return l
+}
+
+// Load loads configuration into the provided struct
+func (l *Loader) Load(config interface{}) error {
+	return l.LoadContext(context.Background(), config)
+}
+
+// LoadContext loads configuration into the provided struct. The context is
+// passed to sources implementing ContextSource and cancels outstanding
+// fetches when sources are loaded in parallel.
+func (l *Loader) LoadContext(ctx context.Context, config interface{}) error {
+	if err := errors.Join(l.setupErrs...); err != nil {
+		return err
+	}
+
+	for _, fn := range l.preLoad {
+		if err := fn(); err != nil {
+			return fmt.Errorf("pre-load hook failed: %w", err)
+		}
+	}
+
+	merged, err := l.loadSources(ctx)
+	if err != nil {
+		return err
+	}
+	l.values = merged.values
+	l.warnings = nil
+
+	// Apply to struct
+	if err := l.applyToStruct(config, merged); err != nil {
+		return err
+	}
+	for _, g := range l.groups {
+		v := reflect.ValueOf(g.target)
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("group %s: target must be a pointer to struct", g.prefix)
+		}
+		if err := l.applyFields(v.Elem(), merged, joinPath(l.scope, g.prefix)); err != nil {
+			return err
+		}
+	}
+	if len(merged.failures) > 0 {
+		return merged.failures
+	}
+
+	if l.strict || l.onUnknown != nil {
+		if err := l.checkUnknownKeys(config, merged); err != nil {
+			return err
+		}
+	}
+
+	for _, fn := range l.postLoad {
+		if err := fn(config); err != nil {
+			return fmt.Errorf("post-load hook failed: %w", err)
+		}
+	}
+
+	if err := l.callValidate(reflect.ValueOf(config).Elem(), ""); err != nil {
+		return err
+	}
+	for _, g := range l.groups {
+		if err := l.callValidate(reflect.ValueOf(g.target).Elem(), g.prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reload loads configuration again and applies changed fields to the
+// already populated config and group targets. It returns the names of the
+// fields that changed, with fields of nested structs named by their path
+// and group fields prefixed with the group's prefix, e.g. "cache.URL".
+// Fields tagged with the noreload option keep their current value; their
+// attempted changes are reported as "Name (noreload)".
+func (l *Loader) Reload(config interface{}) ([]string, error) {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct")
+	}
+
+	// Groups are loaded into fresh copies as well, so their changes go
+	// through reloadFields like the main config's
+	targets := make([]interface{}, len(l.groups))
+	for i, g := range l.groups {
+		targets[i] = g.target
+		if t := reflect.TypeOf(g.target); t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			l.groups[i].target = reflect.New(t.Elem()).Interface()
+		}
+	}
+	fresh := reflect.New(v.Elem().Type())
+	err := l.Load(fresh.Interface())
+	next := make([]interface{}, len(l.groups))
+	for i := range l.groups {
+		next[i] = l.groups[i].target
+		l.groups[i].target = targets[i]
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changed := l.reloadFields(v.Elem(), fresh.Elem(), "")
+	for i, g := range l.groups {
+		changed = append(changed, l.reloadFields(reflect.ValueOf(targets[i]).Elem(), reflect.ValueOf(next[i]).Elem(), g.prefix)...)
+	}
+	return changed, nil
+}
+
+// reloadFields copies the fields of next that differ into current and
+// returns their names. Nested structs are compared field by field, so a
+// noreload field inside them is kept too; their fields are named by path,
+// e.g. "Database.URL".
+func (l *Loader) reloadFields(current, next reflect.Value, path string) []string {
+	t := current.Type()
+	var changed []string
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		cfg := l.getFieldConfig(t.Field(i))
+		if cfg.skip || (cfg.cfgKey == "" && cfg.envKey == "" && !isNestedStruct(field.Type())) {
+			continue
+		}
+
+		name := t.Field(i).Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if isNestedStruct(field.Type()) && !cfg.has("noreload") {
+			changed = append(changed, l.reloadFields(field, next.Field(i), name)...)
+			continue
+		}
+
+		if reflect.DeepEqual(field.Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		if cfg.has("noreload") {
+			changed = append(changed, name+" (noreload)")
+			continue
+		}
+		field.Set(next.Field(i))
+		changed = append(changed, name)
+	}
+
+	return changed
+}
+
+// LoadInto loads only the section of config at path, a dotted cfg key
+// such as "database", leaving every other field untouched. The section
+// must be a nested struct field. Sources are read as for Load, but only
+// the keys below path are applied, which suits targeted reloads.
+func (l *Loader) LoadInto(config interface{}, path string) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to struct")
+	}
+	section, err := l.sectionField(v.Elem(), path)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(l.setupErrs...); err != nil {
+		return err
+	}
+
+	for _, fn := range l.preLoad {
+		if err := fn(); err != nil {
+			return fmt.Errorf("pre-load hook failed: %w", err)
+		}
+	}
+
+	merged, err := l.loadSources(context.Background())
+	if err != nil {
+		return err
+	}
+	l.values = merged.values
+	l.warnings = nil
+
+	if err := l.applyFields(section, merged, joinPath(l.scope, path)); err != nil {
+		return err
+	}
+	if len(merged.failures) > 0 {
+		return merged.failures
+	}
+
+	for _, fn := range l.postLoad {
+		if err := fn(config); err != nil {
+			return fmt.Errorf("post-load hook failed: %w", err)
+		}
+	}
+
+	return l.callValidate(section, path)
+}
+
+// sectionField finds the nested struct field of v whose key is path,
+// following untagged nested structs that share their parent's prefix
+func (l *Loader) sectionField(v reflect.Value, path string) (reflect.Value, error) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !t.Field(i).IsExported() || !isNestedStruct(field.Type()) {
+			continue
+		}
+		cfg := l.getFieldConfig(t.Field(i))
+		if cfg.skip {
+			continue
+		}
+
+		rest, ok := path, cfg.cfgKey == ""
+		if !ok {
+			if path == cfg.cfgKey {
+				return field, nil
+			}
+			rest, ok = strings.CutPrefix(path, cfg.cfgKey+".")
+		}
+		if ok {
+			if section, err := l.sectionField(field, rest); err == nil {
+				return section, nil
+			}
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("no struct section for key %s", path)
+}
+
+// ChangedFromDefaults returns the names of the fields of a populated config
+// whose value differs from their default, default-json or DefaultFunc
+// value. Fields without a default are reported when they are not zero.
+// Fields of nested structs are named by their path, e.g. "Database.URL".
+func (l *Loader) ChangedFromDefaults(config interface{}) ([]string, error) {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct")
+	}
+	return l.changedFields(v.Elem(), "")
+}
+
+func (l *Loader) changedFields(v reflect.Value, path string) ([]string, error) {
+	t := v.Type()
+	var changed []string
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		cfg := l.getFieldConfig(fieldType)
+		if cfg.skip {
+			continue
+		}
+
+		name := fieldType.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		field := v.Field(i)
+		if isNestedStruct(field.Type()) {
+			nested, err := l.changedFields(field, name)
+			if err != nil {
+				return nil, err
+			}
+			changed = append(changed, nested...)
+			continue
+		}
+
+		def := reflect.New(field.Type()).Elem()
+		if err := l.applyDefault(def, name, cfg); err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(field.Interface(), def.Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
+// ReloadSources is like Reload but only re-reads the sources for which
+// match returns true. The others contribute the data of their last load,
+// e.g. to reload files on SIGHUP without re-reading the environment.
+func (l *Loader) ReloadSources(config interface{}, match func(Source) bool) ([]string, error) {
+	l.refresh = match
+	defer func() { l.refresh = nil }()
+	return l.Reload(config)
+}
+
+// sourceData is the merged result of loading all sources
+type sourceData struct {
+	values   map[string]interface{}
+	sourced  map[string]bool   // keys contributed by non-environment sources
+	fromEnv  map[string]bool   // keys whose final value came from the environment
+	fromFile map[string]bool   // keys whose final value came from a file-like source
+	pinned   map[string]bool   // keys set by env bindings or set args, which beat env-named keys
+	envNames map[string]string // variable each env-sourced key was read from
+
+	failures ValidationErrors // validation failures gathered in collect mode
+
+	lists map[string][]interface{} // list values merged across sources, for the merge option
+}
+
+// loadSources reads every source and merges the results in order
+func (l *Loader) loadSources(ctx context.Context) (*sourceData, error) {
+	results := make([]map[string]interface{}, len(l.sources))
+	errs := make([]error, len(l.sources))
+
+	if l.parallel {
+		var wg sync.WaitGroup
+		for i, source := range l.sources {
+			wg.Add(1)
+			go func(i int, source Source) {
+				defer wg.Done()
+				results[i], errs[i] = l.fetchSource(ctx, i, source)
+			}(i, source)
+		}
+
+		// Every fetch is waited for, so none outlives the load and touches
+		// loader or source state afterwards. Context sources return as
+		// soon as ctx is canceled.
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		for i, source := range l.sources {
+			results[i], errs[i] = l.fetchSource(ctx, i, source)
+			if errs[i] != nil && !l.continueOnError {
+				return nil, errs[i]
+			}
+		}
+	}
+
+	if err := l.checkSourceErrors(errs); err != nil {
+		return nil, err
+	}
+
+	// Remember each source's data so ReloadSources can reuse it
+	if len(l.lastResults) != len(results) {
+		l.lastResults = make([]map[string]interface{}, len(results))
+	}
+	for i, data := range results {
+		if errs[i] == nil {
+			l.lastResults[i] = data
+		}
+	}
+
+	// Merge data from all sources
+	merged := &sourceData{
+		values:   make(map[string]interface{}),
+		sourced:  make(map[string]bool),
+		fromEnv:  make(map[string]bool),
+		fromFile: make(map[string]bool),
+		pinned:   make(map[string]bool),
+		envNames: make(map[string]string),
+		lists:    make(map[string][]interface{}),
+	}
+	for _, i := range l.mergeOrder() {
+		data := l.canonicalKeys(results[i])
+		mergeMaps(merged.values, data)
+		env, isEnv := l.sources[i].(*EnvSource)
+		isFile := isFileSource(l.sources[i])
+		if isEnv {
+			for k, variable := range env.names {
+				merged.envNames[l.canonicalKey(k)] = variable
+			}
+		}
+		for k, v := range data {
+			merged.fromEnv[k] = isEnv
+			merged.fromFile[k] = isFile
+			if !isEnv {
+				delete(merged.envNames, k)
+			}
+			if !isEnv {
+				merged.sourced[k] = true
+			}
+			// A list extends the lists of lower priority sources; any
+			// other value replaces them
+			if items, ok := toList(v); ok {
+				merged.lists[k] = appendUnique(merged.lists[k], items)
+			} else {
+				delete(merged.lists, k)
+			}
+		}
+	}
+
+	if l.mergeSlices {
+		for k, list := range merged.lists {
+			merged.values[k] = list
+		}
+	}
+
+	// Explicit env bindings override every source
+	for key, name := range l.envBindings {
+		key = l.canonicalKey(key)
+		if value, ok := os.LookupEnv(name); ok {
+			merged.values[key] = parseValue(value)
+			merged.fromEnv[key] = true
+			merged.envNames[key] = name
+			merged.pinned[key] = true
+			delete(merged.lists, key)
+		}
+	}
+
+	// Set arguments override everything, including env bindings
+	for _, pair := range l.setArgs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = l.canonicalKey(strings.TrimSpace(key))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid set argument %q: expected key=value", pair)
+		}
+		merged.values[key] = parseValue(value)
+		merged.sourced[key] = true
+		merged.fromEnv[key] = false
+		delete(merged.envNames, key)
+		merged.pinned[key] = true
+		delete(merged.lists, key)
+	}
+
+	if l.interpolate {
+		if err := interpolateSourceData(merged); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeOrder returns the indices of the sources in the order they are
+// merged: the order they were added, except that with FilesOverrideEnv the
+// env sources move just before the first file source
+func (l *Loader) mergeOrder() []int {
+	order := make([]int, 0, len(l.sources))
+	firstFile := -1
+	for i, source := range l.sources {
+		if l.filesOverrideEnv && firstFile < 0 && isFileSource(source) {
+			firstFile = len(order)
+		}
+		order = append(order, i)
+	}
+	if firstFile < 0 {
+		return order
+	}
+
+	var env, rest []int
+	for _, i := range order[firstFile:] {
+		if _, isEnv := l.sources[i].(*EnvSource); isEnv {
+			env = append(env, i)
+		} else {
+			rest = append(rest, i)
+		}
+	}
+	return append(append(order[:firstFile:firstFile], env...), rest...)
+}
+
+// isFileSource reports whether a source reads a config document: a file,
+// file layers, a reader (including inline YAML and JSON) or an archive.
+// Required and caching wrappers are classified by the source they wrap.
+func isFileSource(source Source) bool {
+	switch s := source.(type) {
+	case *FileSource, *FileLayersSource, *ReaderSource, *ArchiveSource:
+		return true
+	case *RequiredSource:
+		return isFileSource(s.Source)
+	case *CachingSource:
+		return isFileSource(s.Source)
+	}
+	return false
+}
+
+// fetchSource loads source i, or reuses its last result when a reload is
+// limited to other sources
+func (l *Loader) fetchSource(ctx context.Context, i int, source Source) (map[string]interface{}, error) {
+	if l.refresh != nil && i < len(l.lastResults) && l.lastResults[i] != nil && !l.refresh(source) {
+		return l.lastResults[i], nil
+	}
+	return loadSource(ctx, source)
+}
+
+// checkSourceErrors decides whether failed sources abort the load. With
+// ContinueOnSourceError they are skipped and recorded, unless a required
+// source failed or no source loaded at all.
+func (l *Loader) checkSourceErrors(errs []error) error {
+	if !l.continueOnError {
+		return errors.Join(errs...)
+	}
+
+	var failed, required []error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failed = append(failed, err)
+		if _, ok := l.sources[i].(*RequiredSource); ok {
+			required = append(required, err)
+		}
+	}
+	l.sourceErrors = failed
+
+	if len(required) > 0 {
+		return errors.Join(required...)
+	}
+	if len(failed) > 0 && len(failed) == len(errs) {
+		return errors.Join(failed...)
+	}
+	return nil
+}
+
+// interpolateSourceData expands environment references in every merged
+// value, reporting the key of the first one that fails. Values taken from
+// the environment are left as they are: the shell has already expanded
+// them and unrelated variables must not fail the load.
+func interpolateSourceData(merged *sourceData) error {
+	for key, value := range merged.values {
+		if merged.fromEnv[key] {
+			continue
+		}
+		expanded, err := interpolateValue(value)
+		if err != nil {
+			return fmt.Errorf("failed to interpolate %s: %w", key, err)
+		}
+		merged.values[key] = expanded
+	}
+	for key, list := range merged.lists {
+		if merged.fromEnv[key] {
+			continue
+		}
+		expanded, err := interpolateValue(list)
+		if err != nil {
+			return fmt.Errorf("failed to interpolate %s: %w", key, err)
+		}
+		merged.lists[key] = expanded.([]interface{})
+	}
+	return nil
+}
+
+// checkUnknownKeys reports keys from non-environment sources that no
+// field of config declares. Each key is passed to the OnUnknownKey
+// callback; in strict mode they also fail the load.
+func (l *Loader) checkUnknownKeys(config interface{}, merged *sourceData) error {
+	known := make(map[string]bool)
+	var prefixes []string
+	l.collectKnownKeys(reflect.TypeOf(config).Elem(), l.scope, known, &prefixes)
+	for _, g := range l.groups {
+		l.collectKnownKeys(reflect.TypeOf(g.target).Elem(), joinPath(l.scope, g.prefix), known, &prefixes)
+	}
+
+	var unknown []string
+	for key := range merged.sourced {
+		if known[key] || hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		// A scoped loader only owns the keys below its prefix
+		if l.scope != "" && !strings.HasPrefix(key, l.scope+".") {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+
+	if l.onUnknown != nil {
+		for _, key := range unknown {
+			l.onUnknown(key, merged.values[key])
+		}
+	}
+
+	if l.strict && len(unknown) > 0 {
+		return fmt.Errorf("unknown configuration keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// collectKnownKeys records the keys declared by the fields of t. Keys of
+// leaf fields are also recorded as prefixes so map and variant subtrees
+// count as known.
+func (l *Loader) collectKnownKeys(t reflect.Type, prefix string, known map[string]bool, prefixes *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		cfg := l.getFieldConfig(t.Field(i))
+		if cfg.skip {
+			continue
+		}
+		key := l.canonicalKey(cfg.cfgKey)
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		}
+
+		if isNestedStruct(t.Field(i).Type) {
+			nestedPrefix := prefix
+			if key != "" {
+				nestedPrefix = key
+			}
+			l.collectKnownKeys(t.Field(i).Type, nestedPrefix, known, prefixes)
+			continue
+		}
+
+		if cfg.has("remaining") {
+			// A catch-all field owns every key below its struct
+			*prefixes = append(*prefixes, joinPath(prefix, ""))
+		} else if wildcard, ok := strings.CutSuffix(key, "*"); ok {
+			*prefixes = append(*prefixes, wildcard)
+		} else if key != "" {
+			known[key] = true
+			*prefixes = append(*prefixes, key+".")
+		}
+		if cfg.envKey != "" {
+			known[l.canonicalKey(strings.ToLower(cfg.envKey))] = true
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSource loads a single source, passing ctx through when supported
+func loadSource(ctx context.Context, source Source) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	var err error
+	if cs, ok := source.(ContextSource); ok {
+		data, err = cs.LoadContext(ctx)
+	} else {
+		data, err = source.Load()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load from source: %w", err)
+	}
+	return data, nil
+}
+
+// validatable is implemented by config structs that check their own
+// invariants once all fields are populated
+type validatable interface {
+	Validate() error
+}
+
+// callValidate invokes Validate on nested structs first, then on v itself.
+// Fields tagged cfg:"-" are not descended into.
+func (l *Loader) callValidate(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !t.Field(i).IsExported() || l.getFieldConfig(t.Field(i)).skip {
+			continue
+		}
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+
+		name := t.Field(i).Name
+		if path != "" {
+			name = path + "." + name
+		}
+		if err := l.callValidate(field, name); err != nil {
+			return err
+		}
+	}
+
+	target := v.Interface()
+	if v.CanAddr() {
+		target = v.Addr().Interface()
+	}
+	if val, ok := target.(validatable); ok {
+		if err := val.Validate(); err != nil {
+			if path == "" {
+				return fmt.Errorf("config validation failed: %w", err)
+			}
+			return fmt.Errorf("config validation failed for %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// FileSource loads configuration from files
+type FileSource struct {
+	Path    string
+	Format  string // overrides detection from the file extension
+	options *fileOptions
+}
+
+// fileOptions holds loader-level settings shared by the file sources it adds
+type fileOptions struct {
+	jsonc    bool // treat .json files as JSONC
+	template bool // render files with text/template before parsing
+	strict   bool // reject duplicate keys and extra documents
+}
+
+func (fs *FileSource) Priority() int { return 1 }
+
+func (fs *FileSource) Load() (map[string]interface{}, error) {
+	data, err := os.ReadFile(fs.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]interface{}), nil // File doesn't exist, return empty
+		}
+		return nil, err
+	}
+
+	if fs.options != nil && fs.options.template {
+		if data, err = renderTemplate(data, fs.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Determine format by extension unless set explicitly
+	ext := fs.Path[strings.LastIndex(fs.Path, ".")+1:]
+	if fs.Format != "" {
+		ext = fs.Format
+	}
+	format := fs.options.format(ext)
+
+	if fs.options != nil && fs.options.strict {
+		if err := checkStrictDecode(data, format); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", fs.Path, err)
+		}
+	}
+
+	return parseData(data, format, fs.Path)
+}
+
+// format normalizes a file extension or format name, applying the
+// loader's JSONC setting
+func (o *fileOptions) format(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext == "json" && o != nil && o.jsonc {
+		ext = "jsonc"
+	}
+	return ext
+}
+
+// FileLayersSource loads an ordered list of files as one source, later
+// files overriding earlier ones
+type FileLayersSource struct {
+	Paths   []string
+	options *fileOptions
+}
+
+func (ls *FileLayersSource) Priority() int { return 1 } // Same as files
+
+func (ls *FileLayersSource) Load() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, path := range ls.Paths {
+		layer := &FileSource{Path: path, options: ls.options}
+		data, err := layer.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load layer %s: %w", path, err)
+		}
+		mergeMaps(result, data)
+	}
+	return result, nil
+}
+
+// ReaderSource loads configuration from a reader in the given format
+// (json, jsonc, yaml). The reader is consumed on the first load and its
+// contents are reused afterwards.
+type ReaderSource struct {
+	Reader io.Reader
+	Format string
+
+	data []byte
+	read bool
+}
+
+func (rs *ReaderSource) Priority() int { return 1 } // Same as files
+
+func (rs *ReaderSource) Load() (map[string]interface{}, error) {
+	if !rs.read {
+		data, err := io.ReadAll(rs.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s config: %w", rs.Format, err)
+		}
+		rs.data = data
+		rs.read = true
+	}
+
+	return parseData(rs.data, rs.Format, rs.Format+" reader")
+}
+
+// RemoteFuncSource loads configuration through a caller-supplied fetch
+// function returning raw bytes and their format. It lets any transport
+// (gRPC, custom RPC, ...) be plugged in behind a closure.
+type RemoteFuncSource struct {
+	Fetch func(ctx context.Context) ([]byte, string, error)
+}
+
+func (rs *RemoteFuncSource) Priority() int { return 1 } // Same as files
+
+func (rs *RemoteFuncSource) Load() (map[string]interface{}, error) {
+	return rs.LoadContext(context.Background())
+}
+
+func (rs *RemoteFuncSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	data, format, err := rs.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote config: %w", err)
+	}
+	return parseData(data, format, "remote "+format+" config")
+}
+
+// parseData parses raw config data in the given format and flattens it
+func parseData(data []byte, format, name string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	var err error
+
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(data, &result)
+	case "jsonc":
+		err = json.Unmarshal(stripJSONC(data), &result)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &result)
+	case "ini":
+		result, err = parseINI(data)
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return flattenMap(result, ""), nil
+}
+
+// EnvSource loads configuration from environment variables
+type EnvSource struct {
+	options *envOptions
+	names   map[string]string // variable each key of the last load came from
+}
+
+// envOptions holds loader-level settings shared by the env sources it adds
+type envOptions struct {
+	fileSecrets bool
+	mapper      func(key, value string) (string, string, bool)
+	jsonVars    map[string]bool // upper-cased names of variables holding JSON
+}
+
+func (es *EnvSource) Priority() int { return 2 } // Higher priority than files
+
+func (es *EnvSource) Load() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	names := make(map[string]string)
+	secretFiles := make(map[string]string)
+	secretVars := make(map[string]string)
+
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) == 2 {
+			name, value := parts[0], parts[1]
+			variable := name
+			if es.options != nil && es.options.mapper != nil {
+				var keep bool
+				if name, value, keep = es.options.mapper(name, value); !keep {
+					continue
+				}
+			}
+			key := strings.ToLower(name)
+			if base, ok := strings.CutSuffix(key, "_file"); ok && base != "" {
+				secretFiles[base] = value
+				secretVars[base] = variable
+			}
+
+			if es.options != nil && es.options.jsonVars[strings.ToUpper(name)] {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+					return nil, fmt.Errorf("invalid JSON in %s: %w", name, err)
+				}
+				if obj, ok := decoded.(map[string]interface{}); ok {
+					for k, v := range flattenMap(obj, key) {
+						result[k] = v
+						names[k] = variable
+					}
+				} else {
+					result[key] = decoded
+					names[key] = variable
+				}
+				continue
+			}
+
+			// Try to parse as different types
+			if parsed := parseValue(value); parsed != nil {
+				result[key] = parsed
+			} else {
+				result[key] = value
+			}
+			names[key] = variable
+		}
+	}
+
+	if es.options != nil && es.options.fileSecrets {
+		resolveFileSecrets(result, secretFiles)
+		for key, variable := range secretVars {
+			if _, ok := names[key]; !ok && result[key] != nil {
+				names[key] = variable
+			}
+		}
+	}
+
+	es.names = names
+	return result, nil
+}
+
+// resolveFileSecrets sets each key from the file its KEY_FILE variable
+// references, unless the key is already set. Variables such as LOG_FILE
+// or PID_FILE often name files unrelated to config, so paths that cannot
+// be read are skipped rather than failing the load; a missing secret is
+// left to the field's required rule.
+func resolveFileSecrets(result map[string]interface{}, files map[string]string) {
+	for key, path := range files {
+		if _, exists := result[key]; exists {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		result[key] = strings.TrimRight(string(content), "\r\n")
+	}
+}
+
+// MapSource loads from a map (useful for defaults)
+type MapSource struct {
+	Data map[string]interface{}
+}
+
+func (ms *MapSource) Priority() int { return 0 } // Lowest priority
+
+func (ms *MapSource) Load() (map[string]interface{}, error) {
+	return flattenMap(ms.Data, ""), nil
+}
+
+// ResolvedMapSource loads from a map keyed by dotted paths
+type ResolvedMapSource struct {
+	Data map[string]interface{}
+}
+
+func (rs *ResolvedMapSource) Priority() int { return 0 } // Same as maps
+
+func (rs *ResolvedMapSource) Load() (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(rs.Data))
+	for k, v := range rs.Data {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// RequiredSource marks a source whose failure aborts the load even with
+// ContinueOnSourceError
+type RequiredSource struct {
+	Source Source
+}
+
+// Required wraps source so that its failure always fails the load
+func Required(source Source) *RequiredSource {
+	return &RequiredSource{Source: source}
+}
+
+func (rs *RequiredSource) Priority() int { return rs.Source.Priority() }
+
+func (rs *RequiredSource) Load() (map[string]interface{}, error) {
+	return rs.Source.Load()
+}
+
+func (rs *RequiredSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	if cs, ok := rs.Source.(ContextSource); ok {
+		return cs.LoadContext(ctx)
+	}
+	return rs.Source.Load()
+}
+
+// CachingSource wraps another source and reuses its last successful
+// result until the TTL expires
+type CachingSource struct {
+	Source     Source
+	TTL        time.Duration
+	ServeStale bool // return the cached result when a refetch fails
+
+	mu        sync.Mutex
+	cached    map[string]interface{}
+	fetchedAt time.Time
+}
+
+// NewCachingSource creates a caching wrapper around source
+func NewCachingSource(source Source, ttl time.Duration) *CachingSource {
+	return &CachingSource{Source: source, TTL: ttl}
+}
+
+func (cs *CachingSource) Priority() int { return cs.Source.Priority() }
+
+func (cs *CachingSource) Load() (map[string]interface{}, error) {
+	return cs.LoadContext(context.Background())
+}
+
+func (cs *CachingSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.cached != nil && time.Since(cs.fetchedAt) < cs.TTL {
+		return cs.cached, nil
+	}
+
+	data, err := loadSource(ctx, cs.Source)
+	if err != nil {
+		if cs.ServeStale && cs.cached != nil {
+			return cs.cached, nil
+		}
+		return nil, err
+	}
+
+	cs.cached = data
+	cs.fetchedAt = time.Now()
+	return data, nil
+}
+
+// Helper functions
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// toList returns the elements of v when it is a slice
+func toList(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// appendUnique appends the items not already in list, keeping the order in
+// which they were first seen
+func appendUnique(list, items []interface{}) []interface{} {
+	for _, item := range items {
+		seen := false
+		for _, existing := range list {
+			if reflect.DeepEqual(existing, item) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func flattenMap(m map[string]interface{}, prefix string) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok {
+			for nk, nv := range flattenMap(nested, key) {
+				result[nk] = nv
+			}
+		} else {
+			result[key] = v
+		}
+	}
+
+	return result
+}
+
+// ParseValue converts a raw string the way environment variables are
+// converted: to a bool, int64 or float64 when it parses as one, otherwise
+// it is returned unchanged. Custom sources can use it for string-only
+// backends.
+func ParseValue(s string) interface{} {
+	return parseValue(s)
+}
+
+func parseValue(s string) interface{} {
+	// Try boolean
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+
+	// Try int
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	// Try float
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+
+	return s // Return as string
+}
+
+func (l *Loader) applyToStruct(config interface{}, merged *sourceData) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to struct")
+	}
+
+	if l.requireTags {
+		if untagged := l.untaggedFields(v.Elem().Type(), ""); len(untagged) > 0 {
+			return fmt.Errorf("fields without cfg tags: %s", strings.Join(untagged, ", "))
+		}
+	}
+
+	return l.applyFields(v.Elem(), merged, l.scope)
+}
+
+// untaggedFields lists the settable fields of t, including those of nested
+// structs, that have no cfg tag
+func (l *Loader) untaggedFields(t reflect.Type, path string) []string {
+	var untagged []string
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		name := fieldType.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		cfg := l.getFieldConfig(fieldType)
+		if cfg.skip {
+			continue
+		}
+		if isNestedStruct(fieldType.Type) {
+			untagged = append(untagged, l.untaggedFields(fieldType.Type, name)...)
+			continue
+		}
+		if !cfg.tagged {
+			untagged = append(untagged, name)
+		}
+	}
+	return untagged
+}
+
+// isStructElem reports whether t, or the type it points to, is a struct
+// loaded from an object, as map and slice elements of struct type are
+func isStructElem(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != durationType
+}
+
+// isNestedStruct reports whether a field is a struct whose fields are
+// loaded individually rather than converted as a single value
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// applyFields populates the fields of struct v. Keys of fields are
+// relative to prefix; a nested struct field extends the prefix with its
+// own cfg key, or shares the parent's prefix when it has none.
+func (l *Loader) applyFields(v reflect.Value, merged *sourceData, prefix string) error {
+	t := v.Type()
+	data := merged.values
+
+	type pending struct {
+		ctx   fieldContext
+		rules string
+	}
+	var deferred []pending
+	var remaining reflect.Value
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+
+		if !field.CanSet() {
+			continue
+		}
+
+		// Get field configuration
+		cfg := l.getFieldConfig(fieldType)
+		if cfg.skip {
+			continue
+		}
+		if prefix != "" && cfg.cfgKey != "" {
+			cfg.cfgKey = prefix + "." + cfg.cfgKey
+		}
+
+		// Filled with the keys no other field consumed once the rest are set
+		if cfg.has("remaining") && field.Kind() == reflect.Map {
+			remaining = field
+			continue
+		}
+
+		if isNestedStruct(field.Type()) {
+			nestedPrefix := prefix
+			if cfg.cfgKey != "" {
+				nestedPrefix = cfg.cfgKey
+			}
+			if err := l.applyFields(field, merged, nestedPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ctx := fieldContext{name: fieldType.Name, field: field, parent: v, data: data, secret: cfg.has("secret")}
+		if cfg.validate != "" {
+			if l.strictValidators {
+				if err := l.checkRuleNames(fieldType.Name, cfg.validate); err != nil {
+					return err
+				}
+			}
+			deferred = append(deferred, pending{ctx, cfg.validate})
+		}
+
+		// Polymorphic sections are decoded by their registered variants
+		if field.Kind() == reflect.Interface && cfg.cfgKey != "" {
+			if err := l.applyVariant(field, cfg.cfgKey, data); err != nil {
+				return fmt.Errorf("failed to set field %s: %w", fieldType.Name, err)
+			}
+			continue
+		}
+
+		// Find value from sources
+		value, key := l.findValue(merged, cfg)
+		if cfg.envRequired {
+			// Secrets that must not come from files are read from the
+			// environment only
+			raw, ok := os.LookupEnv(cfg.envKey)
+			if !ok {
+				return fmt.Errorf("field %s requires environment variable %s", fieldType.Name, cfg.envKey)
+			}
+			value, key = parseValue(raw), strings.ToLower(cfg.envKey)
+		}
+		if list, ok := merged.lists[key]; ok && cfg.has("merge") {
+			value = list
+		}
+		if l.emptyEnvTrue && field.Kind() == reflect.Bool && value == "" && merged.fromEnv[key] {
+			// A flag variable that is merely set, like DEBUG=, means true
+			value = true
+		}
+		if wildcard, ok := strings.CutSuffix(cfg.cfgKey, "*"); ok && field.Kind() == reflect.Map {
+			// cfg:"feature_flag_*" gathers every matching key into the map
+			value, key = nil, ""
+			if sub := collectWildcard(data, l.canonicalKey(wildcard)); sub != nil {
+				value = sub
+			}
+		}
+		if value == nil && field.Kind() == reflect.Map {
+			if sub := collectPrefix(data, l.canonicalKey(cfg.cfgKey)); sub != nil {
+				value = sub
+			}
+		}
+
+		if value != nil && cfg.has("base64") {
+			decoded, err := base64.StdEncoding.DecodeString(fmt.Sprintf("%v", value))
+			if err != nil {
+				return fmt.Errorf("failed to decode base64 for field %s: %w", fieldType.Name, err)
+			}
+			if field.Kind() == reflect.String {
+				value = string(decoded)
+			} else {
+				value = decoded
+			}
+		}
+
+		if value != nil && cfg.has("quantity") {
+			n, err := parseQuantity(value)
+			if err != nil {
+				return fmt.Errorf("failed to set field %s: %w", fieldType.Name, err)
+			}
+			value = n
+		}
+
+		if value != nil {
+			// Validate if needed
+			if cfg.validate != "" {
+				transformed, err := l.validateField(ctx, value, cfg.validate)
+				if err != nil {
+					if err := l.collectError(merged, err); err != nil {
+						return err
+					}
+					continue
+				}
+				value = transformed
+			}
+
+			if parse, ok := l.fieldParsers[cfg.cfgKey]; ok {
+				parsed, err := parse(value)
+				if err != nil {
+					return fmt.Errorf("failed to parse field %s: %w", fieldType.Name, err)
+				}
+				value = parsed
+			}
+
+			if cfg.timeFormat != "" && field.Type() == timeType {
+				parsed, err := parseTime(value, cfg.timeFormat)
+				if err != nil {
+					return fmt.Errorf("failed to set field %s: %w", fieldType.Name, err)
+				}
+				value = parsed
+			}
+
+			// Set value
+			if err := l.setValue(field, value); err != nil {
+				var kindErr *UnsupportedKindError
+				if errors.As(err, &kindErr) {
+					kindErr.Field = fieldType.Name
+				}
+				var lossyErr *LossyConversionError
+				if errors.As(err, &lossyErr) {
+					lossyErr.Field = fieldType.Name
+				}
+				if merged.fromEnv[key] {
+					variable, ok := merged.envNames[key]
+					if !ok {
+						variable = strings.ToUpper(key)
+					}
+					return &ConversionError{
+						Variable: variable,
+						Field:    fieldType.Name,
+						Type:     field.Type(),
+						Value:    value,
+						Err:      err,
+					}
+				}
+				return fmt.Errorf("failed to set field %s: %w", fieldType.Name, err)
+			}
+		} else if !l.noDefaults {
+			if err := l.applyDefault(field, fieldType.Name, cfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if remaining.IsValid() {
+		if err := l.applyRemaining(remaining, v.Type(), merged, prefix); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range deferred {
+		if err := l.validateDeferred(p.ctx, p.rules); err != nil {
+			if err := l.collectError(merged, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyRemaining sets a map field tagged cfg:",remaining" to the keys below
+// prefix, relative to it, that no other field of t declares. Like strict
+// mode it only considers keys from non-environment sources.
+func (l *Loader) applyRemaining(field reflect.Value, t reflect.Type, merged *sourceData, prefix string) error {
+	known := make(map[string]bool)
+	var all, prefixes []string
+	l.collectKnownKeys(t, prefix, known, &all)
+	own := joinPath(prefix, "")
+	for _, p := range all {
+		if p != own {
+			prefixes = append(prefixes, p)
+		}
+	}
+
+	leftover := make(map[string]interface{})
+	for key := range merged.sourced {
+		rest, ok := strings.CutPrefix(key, own)
+		if !ok || known[key] || hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		leftover[rest] = merged.values[key]
+	}
+
+	if err := l.setValue(field, leftover); err != nil {
+		return fmt.Errorf("failed to set remaining keys: %w", err)
+	}
+	return nil
+}
+
+// applyDefault sets a field no source provided from its default-json or
+// default tag, falling back to a registered DefaultFunc
+func (l *Loader) applyDefault(field reflect.Value, name string, cfg fieldConfig) error {
+	if cfg.defaultJSON != "" {
+		// Decode JSON default directly into the field
+		if err := json.Unmarshal([]byte(cfg.defaultJSON), field.Addr().Interface()); err != nil {
+			return fmt.Errorf("failed to parse default-json for field %s: %w", name, err)
+		}
+	} else if cfg.defaultValue != "" && cfg.timeFormat != "" && field.Type() == timeType {
+		t, err := parseTime(cfg.defaultValue, cfg.timeFormat)
+		if err != nil {
+			return fmt.Errorf("failed to set default for field %s: %w", name, err)
+		}
+		field.Set(reflect.ValueOf(t))
+	} else if cfg.defaultValue != "" && cfg.has("quantity") {
+		n, err := parseQuantity(cfg.defaultValue)
+		if err != nil {
+			return fmt.Errorf("failed to set default for field %s: %w", name, err)
+		}
+		if err := l.setValue(field, n); err != nil {
+			return fmt.Errorf("failed to set default for field %s: %w", name, err)
+		}
+	} else if cfg.defaultValue != "" {
+		// Use default value
+		if err := l.setDefault(field, cfg.defaultValue); err != nil {
+			return fmt.Errorf("failed to set default for field %s: %w", name, err)
+		}
+	} else if fn, ok := l.defaultFuncs[cfg.cfgKey]; ok && cfg.cfgKey != "" {
+		// Computed default as the last fallback
+		if err := l.setValue(field, fn()); err != nil {
+			return fmt.Errorf("failed to set default for field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// collectError records validation failures in collect mode and returns
+// any other error unchanged
+func (l *Loader) collectError(merged *sourceData, err error) error {
+	if !l.collect {
+		return err
+	}
+
+	var many ValidationErrors
+	if errors.As(err, &many) {
+		merged.failures = append(merged.failures, many...)
+		return nil
+	}
+	var single *ValidationError
+	if errors.As(err, &single) {
+		merged.failures = append(merged.failures, single)
+		return nil
+	}
+	return err
+}
+
+type fieldConfig struct {
+	cfgKey       string
+	envKey       string
+	envRequired  bool // env:"NAME,required"
+	validate     string
+	defaultValue string
+	defaultJSON  string
+	timeFormat   string // "|"-separated layouts for time.Time fields
+	options      map[string]bool
+	skip         bool // cfg:"-"
+	tagged       bool // has a cfg tag at all
+}
+
+// has reports whether the cfg tag carries the given option
+// (e.g. "noreload" in `cfg:"port,noreload"`)
+func (c fieldConfig) has(option string) bool {
+	return c.options[option]
+}
+
+func (l *Loader) getFieldConfig(field reflect.StructField) fieldConfig {
+	tag, tagged := field.Tag.Lookup("cfg")
+	if tag == "-" {
+		// Like encoding/json, "-" ignores the field while "-," names key "-"
+		return fieldConfig{skip: true, tagged: true}
+	}
+	parts := strings.Split(tag, ",")
+	options := make(map[string]bool, len(parts)-1)
+	for _, opt := range parts[1:] {
+		options[strings.TrimSpace(opt)] = true
+	}
+
+	envKey, envOpts, _ := strings.Cut(field.Tag.Get("env"), ",")
+
+	// Fall back to the json tag name, ignoring its options
+	if !tagged && l.jsonFallback {
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			parts[0] = name
+			tagged = true
+		}
+	}
+
+	return fieldConfig{
+		cfgKey:       parts[0],
+		envKey:       envKey,
+		envRequired:  strings.TrimSpace(envOpts) == "required",
+		validate:     field.Tag.Get("validate"),
+		defaultValue: field.Tag.Get("default"),
+		defaultJSON:  field.Tag.Get("default-json"),
+		timeFormat:   field.Tag.Get("timeformat"),
+		options:      options,
+		tagged:       tagged,
+	}
+}
+
+// findValue returns the value for a field and the key it was found under
+func (l *Loader) findValue(merged *sourceData, cfg fieldConfig) (interface{}, string) {
+	data := merged.values
+
+	// Under FilesOverrideEnv a file value also beats env-named keys
+	if l.filesOverrideEnv && merged.fromFile[l.canonicalKey(cfg.cfgKey)] {
+		key := l.canonicalKey(cfg.cfgKey)
+		return data[key], key
+	}
+
+	if len(l.keySeparators) > 0 {
+		cfg.cfgKey = l.canonicalKey(cfg.cfgKey)
+		cfg.envKey = l.canonicalKey(cfg.envKey)
+	}
+
+	// Env bindings and set args override every source, env-named keys
+	// included
+	if merged.pinned[cfg.cfgKey] {
+		return data[cfg.cfgKey], cfg.cfgKey
+	}
+
+	// Check environment key first (higher priority)
+	if cfg.envKey != "" {
+		key := strings.ToLower(cfg.envKey)
+		if value, ok := data[key]; ok {
+			return value, key
+		}
+	}
+
+	// A dotted key, e.g. from nested structs, is also overridden by the
+	// variable with its dots replaced by underscores (DATABASE_URL)
+	if envKey := strings.ReplaceAll(cfg.cfgKey, ".", "_"); envKey != cfg.cfgKey && merged.fromEnv[envKey] {
+		return data[envKey], envKey
+	}
+
+	// Check config key
+	if cfg.cfgKey != "" {
+		if value, ok := data[cfg.cfgKey]; ok {
+			return value, cfg.cfgKey
+		}
+	}
+
+	return nil, ""
+}
+
+// applyVariant populates an interface field from the subtree under key,
+// using the variant selected by a registered discriminator key below it
+func (l *Loader) applyVariant(field reflect.Value, key string, data map[string]interface{}) error {
+	for discriminator, variants := range l.variants {
+		rest, ok := strings.CutPrefix(discriminator, key+".")
+		if !ok || strings.Contains(rest, ".") {
+			continue
+		}
+
+		typeName, ok := data[discriminator]
+		if !ok {
+			continue
+		}
+
+		prototype, ok := variants[fmt.Sprintf("%v", typeName)]
+		if !ok {
+			return fmt.Errorf("unknown %s '%v'", discriminator, typeName)
+		}
+
+		elemType := prototype
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		instance := reflect.New(elemType)
+		sub := collectPrefix(data, key)
+		if err := l.applyToStruct(instance.Interface(), &sourceData{values: sub}); err != nil {
+			return err
+		}
+
+		result := instance
+		if prototype.Kind() != reflect.Ptr {
+			result = instance.Elem()
+		}
+		if !result.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("variant %s does not implement %s", result.Type(), field.Type())
+		}
+		field.Set(result)
+		return nil
+	}
+
+	return nil
+}
+
+// collectWildcard gathers all keys starting with prefix into a map keyed
+// by the rest of the key, or returns nil if there are none. Unlike
+// collectPrefix the prefix need not end at a dot, so env variables such as
+// FEATURE_FLAG_X can be matched by feature_flag_.
+func collectWildcard(data map[string]interface{}, prefix string) map[string]interface{} {
+	var result map[string]interface{}
+	for k, v := range data {
+		if rest, ok := strings.CutPrefix(k, prefix); ok && rest != "" {
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			result[rest] = v
+		}
+	}
+
+	return result
+}
+
+// collectPrefix gathers all keys below prefix into a map keyed by the
+// remaining dotted path, or returns nil if there are none
+func collectPrefix(data map[string]interface{}, prefix string) map[string]interface{} {
+	if prefix == "" {
+		return nil
+	}
+
+	var result map[string]interface{}
+	for k, v := range data {
+		if rest, ok := strings.CutPrefix(k, prefix+"."); ok {
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			result[rest] = v
+		}
+	}
+
+	return result
+}
+
+// setDefault applies a default tag value according to the field's kind
+func (l *Loader) setDefault(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+		return nil
+	case reflect.Slice:
+		parts := strings.Split(raw, ",")
+		items := make([]interface{}, len(parts))
+		for i, part := range parts {
+			items[i] = strings.TrimSpace(part)
+		}
+		return l.setValue(field, items)
+	case reflect.Map:
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			return fmt.Errorf("map default must be a JSON object: %w", err)
+		}
+		return l.setValue(field, m)
+	}
+
+	return l.setValue(field, parseValue(raw))
+}
+
+// stripNumericSeparators removes underscores used as digit separators
+// ("1_000_000"), following Go literal rules: each underscore must sit
+// between two digits. Anything else is returned unchanged so it fails to
+// parse as before.
+func stripNumericSeparators(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1]) {
+			return s
+		}
+	}
+	return strings.ReplaceAll(s, "_", "")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDuration converts a duration string like "1m30s" or a plain number
+// of nanoseconds into a time.Duration
+func parseDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(strings.TrimSpace(v))
+	}
+
+	n, err := strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %v", value)
+	}
+	return time.Duration(n), nil
+}
+
+// parseTime converts a string into a time.Time using the first of the
+// "|"-separated layouts that matches, e.g. "2006-01-02T15:04:05Z07:00|02/01/2006"
+func parseTime(value interface{}, layouts string) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+
+	s := strings.TrimSpace(fmt.Sprintf("%v", value))
+	for _, layout := range strings.Split(layouts, "|") {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time: expected layout %s", s, strings.ReplaceAll(layouts, "|", " or "))
+}
+
+func (l *Loader) setValue(field reflect.Value, value interface{}) error {
+	if names, ok := l.enums[field.Type()]; ok {
+		if name, isString := value.(string); isString {
+			n, found := names[name]
+			if !found {
+				return fmt.Errorf("unknown %s value '%s'", field.Type(), name)
+			}
+			if isUintKind(field.Kind()) {
+				if n < 0 || field.OverflowUint(uint64(n)) {
+					return fmt.Errorf("%s value '%s' (%d) overflows %s", field.Type(), name, n, field.Kind())
+				}
+				field.SetUint(uint64(n))
+				return nil
+			}
+			if field.OverflowInt(n) {
+				return fmt.Errorf("%s value '%s' (%d) overflows %s", field.Type(), name, n, field.Kind())
+			}
+			field.SetInt(n)
+			return nil
+		}
+	}
+
+	if field.Type() == durationType {
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if field.Type() == timeType {
+		t, err := parseTime(value, time.RFC3339)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if f, ok := value.(float64); ok && l.floatFormat != 0 {
+			field.SetString(strconv.FormatFloat(f, l.floatFormat, l.floatPrec, 64))
+			break
+		}
+		field.SetString(fmt.Sprintf("%v", value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Legacy configs use true/false for int flags
+		if b, ok := value.(bool); ok {
+			if b {
+				field.SetInt(1)
+			} else {
+				field.SetInt(0)
+			}
+			break
+		}
+		if l.strictTypes {
+			if f, ok := value.(float64); ok && f != math.Trunc(f) {
+				return &LossyConversionError{Value: value, Kind: field.Kind()}
+			}
+		}
+		// Whole floats such as 1e6 from JSON/YAML convert directly, since
+		// their %v form is not a valid integer
+		if f, ok := value.(float64); ok && f == math.Trunc(f) {
+			if f < math.MinInt64 || f >= math.MaxInt64 || field.OverflowInt(int64(f)) {
+				return fmt.Errorf("value %v overflows %s", f, field.Kind())
+			}
+			field.SetInt(int64(f))
+			break
+		}
+		if i, err := strconv.ParseInt(stripNumericSeparators(fmt.Sprintf("%v", value)), 10, 64); err == nil {
+			field.SetInt(i)
+		} else {
+			return err
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := value.(float64); ok && f == math.Trunc(f) {
+			if f < 0 || f >= math.MaxUint64 || field.OverflowUint(uint64(f)) {
+				return fmt.Errorf("value %v overflows %s", f, field.Kind())
+			}
+			field.SetUint(uint64(f))
+			break
+		}
+		if u, err := strconv.ParseUint(stripNumericSeparators(fmt.Sprintf("%v", value)), 10, 64); err == nil {
+			field.SetUint(u)
+		} else {
+			return err
+		}
+	case reflect.Bool:
+		// ...and 1/0 for bools; other numbers are an error
+		switch n := value.(type) {
+		case int, int64, float64:
+			switch fmt.Sprintf("%v", n) {
+			case "1":
+				field.SetBool(true)
+			case "0":
+				field.SetBool(false)
+			default:
+				return fmt.Errorf("cannot convert %v to bool: only 0 and 1 are accepted", n)
+			}
+			return nil
+		}
+		if b, err := strconv.ParseBool(fmt.Sprintf("%v", value)); err == nil {
+			field.SetBool(b)
+		} else {
+			return err
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(stripNumericSeparators(fmt.Sprintf("%v", value)), 64); err == nil {
+			field.SetFloat(f)
+		} else {
+			return err
+		}
+	case reflect.Slice:
+		// []byte takes raw bytes rather than per-element conversion
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			switch b := value.(type) {
+			case []byte:
+				field.SetBytes(b)
+				return nil
+			case string:
+				field.SetBytes([]byte(b))
+				return nil
+			}
+		}
+		var items []interface{}
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice {
+			items = make([]interface{}, rv.Len())
+			for i := range items {
+				items[i] = rv.Index(i).Interface()
+			}
+		} else {
+			// A lone scalar becomes a one-element slice
+			items = []interface{}{value}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := l.setValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		entries := reflect.ValueOf(value)
+		if field.Type().Key().Kind() != reflect.String {
+			if l.strictTypes {
+				return &UnsupportedKindError{Kind: field.Kind()}
+			}
+			return nil
+		}
+		if entries.Kind() != reflect.Map {
+			if l.strictTypes {
+				return fmt.Errorf("expected an object for %s, got %T", field.Type(), value)
+			}
+			return nil
+		}
+		if isStructElem(field.Type().Elem()) {
+			// Flattened subtrees ("premium.rps") are regrouped per entry
+			grouped := make(map[string]interface{})
+			iter := entries.MapRange()
+			for iter.Next() {
+				setPath(grouped, fmt.Sprintf("%v", iter.Key().Interface()), iter.Value().Interface())
+			}
+			entries = reflect.ValueOf(grouped)
+		}
+		m := reflect.MakeMapWithSize(field.Type(), entries.Len())
+		iter := entries.MapRange()
+		for iter.Next() {
+			k := fmt.Sprintf("%v", iter.Key().Interface())
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := l.setValue(elem, iter.Value().Interface()); err != nil {
+				return fmt.Errorf("key %s: %w", k, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), elem)
+		}
+		field.Set(m)
+	case reflect.Ptr:
+		// Only allocate when a value is present, so nil means "not set"
+		ptr := reflect.New(field.Type().Elem())
+		if err := l.setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	case reflect.Interface:
+		// Elements of map[string]interface{} and the like keep their value
+		if value != nil && reflect.TypeOf(value).AssignableTo(field.Type()) {
+			field.Set(reflect.ValueOf(value))
+		} else if value != nil && l.strictTypes {
+			return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+		}
+	case reflect.Struct:
+		// Struct elements, e.g. of a []struct, load like a nested config so
+		// their defaults, validation and special types all apply
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %T", field.Type(), value)
+		}
+		sub := &sourceData{values: flattenMap(entries, "")}
+		if err := l.applyFields(field, sub, ""); err != nil {
+			return err
+		}
+		if len(sub.failures) > 0 {
+			return sub.failures
+		}
+	default:
+		if l.strictTypes {
+			return &UnsupportedKindError{Kind: field.Kind()}
+		}
+	}
+
+	return nil
+}
+
+// validateField runs the rules of a field in order and returns the value
+// to set, which transform validators may have replaced
+func (l *Loader) validateField(ctx fieldContext, value interface{}, rules string) (interface{}, error) {
+	var failures ValidationErrors
+	for _, rule := range l.splitRules(rules) {
+		rule, warn := strings.CutPrefix(rule, "warn:")
+		ruleName, _, _ := strings.Cut(rule, ":")
+
+		// Deferred rules run once the whole struct is populated
+		if deferredRules[ruleName] {
+			continue
+		}
+
+		// Absent values are only checked by deferred rules like required
+		if value == nil {
+			continue
+		}
+
+		var err error
+		if transform, ok := l.transforms[ruleName]; ok {
+			var transformed interface{}
+			if transformed, err = transform.Transform(value); err == nil {
+				value = transformed
+			} else {
+				err = l.validationError(ctx, value, rule, err)
+			}
+		} else {
+			err = l.runRule(ctx, value, rule)
+		}
+		if warn {
+			l.warn(err)
+			continue
+		}
+
+		if err = l.handleFailure(err); err != nil {
+			ve, ok := err.(*ValidationError)
+			if !ok || !l.collect || !l.allRules {
+				return nil, err
+			}
+			failures = append(failures, ve)
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, failures
+	}
+	return value, nil
+}
+
+// validationError builds the error reported for a failed rule
+func (l *Loader) validationError(ctx fieldContext, value interface{}, rule string, err error) *ValidationError {
+	return &ValidationError{
+		Field:   ctx.name,
+		Value:   value,
+		Rule:    rule,
+		Message: err.Error(),
+		verbose: l.verbose,
+		secret:  ctx.secret,
+	}
+}
+
+// deferredRules inspect the populated field or its siblings rather than
+// the raw source value, so they run after every field has been set
+var deferredRules = map[string]bool{
+	"required":         true,
+	"required_with":    true,
+	"required_without": true,
+	"eqfield":          true,
+	"samelen":          true,
+	"ltefield":         true,
+	"gtefield":         true,
+}
+
+// validateDeferred runs the deferred rules of a field against its final value
+func (l *Loader) validateDeferred(ctx fieldContext, rules string) error {
+	var failures ValidationErrors
+	for _, rule := range l.splitRules(rules) {
+		rule, warn := strings.CutPrefix(rule, "warn:")
+		ruleName, _, _ := strings.Cut(rule, ":")
+		if !deferredRules[ruleName] {
+			continue
+		}
+		if warn {
+			l.warn(l.runRule(ctx, ctx.field.Interface(), rule))
+			continue
+		}
+		if err := l.handleFailure(l.runRule(ctx, ctx.field.Interface(), rule)); err != nil {
+			ve, ok := err.(*ValidationError)
+			if !ok || !l.collect || !l.allRules {
+				return err
+			}
+			failures = append(failures, ve)
+		}
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
+
+// warn records the failure of an advisory warn: rule, if any
+func (l *Loader) warn(err error) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		l.warnings = append(l.warnings, ve)
+	}
+}
+
+// handleFailure passes a rule failure through the OnValidationError hook.
+// A nil result means the failure was suppressed.
+func (l *Loader) handleFailure(err error) error {
+	ve, ok := err.(*ValidationError)
+	if !ok || l.onValidationError == nil {
+		return err
+	}
+	return l.onValidationError(*ve)
+}
+
+// runRule runs a single "name:param" rule and wraps any failure in a
+// *ValidationError
+func (l *Loader) runRule(ctx fieldContext, value interface{}, rule string) error {
+	ruleName, param, _ := strings.Cut(rule, ":")
+
+	var err error
+	if validator, ok := l.validators[ruleName]; ok {
+		err = validator(value, param)
+	} else if validator, ok := l.contextual[ruleName]; ok {
+		err = validator(ctx, value, param)
+	}
+	if err != nil {
+		return l.validationError(ctx, value, rule, err)
+	}
+	return nil
+}
+
+// hasValidator reports whether a rule name is registered
+func (l *Loader) hasValidator(name string) bool {
+	if _, ok := l.validators[name]; ok {
+		return true
+	}
+	if _, ok := l.contextual[name]; ok {
+		return true
+	}
+	_, ok := l.transforms[name]
+	return ok
+}
+
+// checkRuleNames reports the rules of a field that have no registered
+// validator
+func (l *Loader) checkRuleNames(field, rules string) error {
+	var unknown []string
+	for _, rule := range l.splitRules(rules) {
+		name, _, _ := strings.Cut(strings.TrimPrefix(rule, "warn:"), ":")
+		if !l.hasValidator(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown validation rules for field %s: %s", field, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// splitRules splits a validate tag on commas, keeping commas that belong
+// to a rule parameter (e.g. "range:1,10") attached to that rule. Only a
+// part that cannot be a rule name, such as a number or duration, continues
+// the previous rule, so a misspelled rule is still reported as unknown.
+func (l *Loader) splitRules(rules string) []string {
+	var result []string
+	for _, part := range strings.Split(rules, ",") {
+		part = strings.TrimSpace(part)
+		name, _, _ := strings.Cut(strings.TrimPrefix(part, "warn:"), ":")
+		if n := len(result); n > 0 && strings.Contains(result[n-1], ":") {
+			if !l.hasValidator(name) && !isRuleName(name) {
+				result[n-1] += "," + part
+				continue
+			}
+		}
+		result = append(result, part)
+	}
+	return result
+}
+
+// isRuleName reports whether s has the form of a rule name: a letter
+// followed by letters, digits and underscores
+func isRuleName(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && (i == 0 || (r != '_' && !unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// lengthOf parses a length rule parameter of the form "N" or "N:unit",
+// where unit is runes (the default) or bytes, and measures value with it.
+// Lists are measured by element count.
+func lengthOf(value interface{}, param string) (want, n int, unit string, err error) {
+	limit, unit, _ := strings.Cut(param, ":")
+	if unit == "" {
+		unit = "runes"
+	}
+
+	want, err = strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("length parameter must be an integer")
+	}
+
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		return want, rv.Len(), "elements", nil
+	}
+
+	str := fmt.Sprintf("%v", value)
+	switch unit {
+	case "runes":
+		n = utf8.RuneCountInString(str)
+	case "bytes":
+		n = len(str)
+	default:
+		return 0, 0, "", fmt.Errorf("unknown length unit '%s'", unit)
+	}
+	return want, n, unit, nil
+}
+
+// compareValues compares two values numerically when both are numbers and
+// as strings otherwise
+func compareValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// Built-in validators
+func getBuiltinValidators() map[string]ValidatorFunc {
+	return map[string]ValidatorFunc{
+		"url": func(value interface{}, param string) error {
+			str := fmt.Sprintf("%v", value)
+			if _, err := url.Parse(str); err != nil {
+				return fmt.Errorf("invalid URL format")
+			}
+			return nil
+		},
+		"duration": func(value interface{}, param string) error {
+			parts := strings.Split(param, ",")
+			if len(parts) != 2 {
+				return fmt.Errorf("duration validator requires min,max parameters")
+			}
+
+			min, err1 := time.ParseDuration(strings.TrimSpace(parts[0]))
+			max, err2 := time.ParseDuration(strings.TrimSpace(parts[1]))
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("duration parameters must be durations")
+			}
+
+			d, err := parseDuration(value)
+			if err != nil {
+				return fmt.Errorf("value must be a duration")
+			}
+
+			if d < min || d > max {
+				return fmt.Errorf("duration must be between %s and %s", min, max)
+			}
+			return nil
+		},
+		"json": func(value interface{}, param string) error {
+			str := strings.TrimSpace(fmt.Sprintf("%v", value))
+			if !json.Valid([]byte(str)) {
+				return fmt.Errorf("value must be valid JSON")
+			}
+
+			switch param {
+			case "":
+			case "object":
+				if !strings.HasPrefix(str, "{") {
+					return fmt.Errorf("value must be a JSON object")
+				}
+			case "array":
+				if !strings.HasPrefix(str, "[") {
+					return fmt.Errorf("value must be a JSON array")
+				}
+			default:
+				return fmt.Errorf("json parameter must be object or array")
+			}
+			return nil
+		},
+		"email": func(value interface{}, param string) error {
+			str := fmt.Sprintf("%v", value)
+			emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+			if !emailRegex.MatchString(str) {
+				return fmt.Errorf("invalid email format")
+			}
+			return nil
+		},
+		"currency":    codeValidator(currencyCodes, "ISO 4217 currency code", "USD"),
+		"countrycode": codeValidator(countryCodes, "ISO 3166-1 alpha-2 country code", "US"),
+		"min": func(value interface{}, param string) error {
+			minVal, err := strconv.Atoi(param)
+			if err != nil {
+				return fmt.Errorf("min parameter must be an integer")
+			}
+
+			val, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return fmt.Errorf("value must be an integer for min validation")
+			}
+
+			if val < minVal {
+				return fmt.Errorf("value must be at least %d", minVal)
+			}
+			return nil
+		},
+		"max": func(value interface{}, param string) error {
+			maxVal, err := strconv.Atoi(param)
+			if err != nil {
+				return fmt.Errorf("max parameter must be an integer")
+			}
+
+			val, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return fmt.Errorf("value must be an integer for max validation")
+			}
+
+			if val > maxVal {
+				return fmt.Errorf("value must be at most %d", maxVal)
+			}
+			return nil
+		},
+		"multipleof": func(value interface{}, param string) error {
+			step, err := strconv.ParseInt(param, 10, 64)
+			if err != nil || step <= 0 {
+				return fmt.Errorf("multipleof parameter must be a positive integer")
+			}
+
+			val, err := strconv.ParseInt(stripNumericSeparators(fmt.Sprintf("%v", value)), 10, 64)
+			if err != nil {
+				return fmt.Errorf("value must be an integer for multipleof validation")
+			}
+
+			if val%step != 0 {
+				return fmt.Errorf("value must be a multiple of %d", step)
+			}
+			return nil
+		},
+		"gt":  numericComparison("gt", "greater than", func(v, p float64) bool { return v > p }),
+		"gte": numericComparison("gte", "greater than or equal to", func(v, p float64) bool { return v >= p }),
+		"lt":  numericComparison("lt", "less than", func(v, p float64) bool { return v < p }),
+		"lte": numericComparison("lte", "less than or equal to", func(v, p float64) bool { return v <= p }),
+		"len": func(value interface{}, param string) error {
+			want, n, unit, err := lengthOf(value, param)
+			if err != nil {
+				return err
+			}
+			if n != want {
+				return fmt.Errorf("length must be exactly %d %s", want, unit)
+			}
+			return nil
+		},
+		"minlen": func(value interface{}, param string) error {
+			want, n, unit, err := lengthOf(value, param)
+			if err != nil {
+				return err
+			}
+			if n < want {
+				return fmt.Errorf("length must be at least %d %s", want, unit)
+			}
+			return nil
+		},
+		"maxlen": func(value interface{}, param string) error {
+			want, n, unit, err := lengthOf(value, param)
+			if err != nil {
+				return err
+			}
+			if n > want {
+				return fmt.Errorf("length must be at most %d %s", want, unit)
+			}
+			return nil
+		},
+		"oneof": func(value interface{}, param string) error {
+			str := fmt.Sprintf("%v", value)
+			for _, allowed := range strings.Fields(param) {
+				if str == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("value must be one of: %s", param)
+		},
+		"oneofci": func(value interface{}, param string) error {
+			str := fmt.Sprintf("%v", value)
+			for _, allowed := range strings.Fields(param) {
+				if strings.EqualFold(str, allowed) {
+					return nil
+				}
+			}
+			return fmt.Errorf("value must be one of (case-insensitive): %s", param)
+		},
+		"within": func(value interface{}, param string) error {
+			if param == "" {
+				return fmt.Errorf("within validator requires a root path")
+			}
+
+			// Containment is checked on the cleaned path; symlinks are
+			// not resolved. Relative values are taken relative to root.
+			root := filepath.Clean(param)
+			path := fmt.Sprintf("%v", value)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			rel, err := filepath.Rel(root, filepath.Clean(path))
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("path must be within %s", root)
+			}
+			return nil
+		},
+		"in_file": func(value interface{}, param string) error {
+			allowed, err := readAllowList(param)
+			if err != nil {
+				return err
+			}
+			if !allowed[fmt.Sprintf("%v", value)] {
+				return fmt.Errorf("value is not listed in %s", param)
+			}
+			return nil
+		},
+		"unique": func(value interface{}, param string) error {
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return fmt.Errorf("value must be a list for unique validation")
+			}
+
+			seen := make(map[string]int, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				str := fmt.Sprintf("%v", rv.Index(i).Interface())
+				if first, ok := seen[str]; ok {
+					return fmt.Errorf("duplicate value '%s' at index %d (first at %d)", str, i, first)
+				}
+				seen[str] = i
+			}
+			return nil
+		},
+		"sorted": func(value interface{}, param string) error {
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return fmt.Errorf("value must be a list for sorted validation")
+			}
+
+			for i := 1; i < rv.Len(); i++ {
+				prev := fmt.Sprintf("%v", rv.Index(i-1).Interface())
+				cur := fmt.Sprintf("%v", rv.Index(i).Interface())
+				if compareValues(prev, cur) > 0 {
+					return fmt.Errorf("value '%s' at index %d is out of order after '%s'", cur, i, prev)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// numericComparison builds a validator comparing a number against its
+// parameter, for integer and float fields alike
+func numericComparison(name, relation string, ok func(value, param float64) bool) ValidatorFunc {
+	return func(value interface{}, param string) error {
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("%s parameter must be a number", name)
+		}
+
+		val, err := strconv.ParseFloat(stripNumericSeparators(fmt.Sprintf("%v", value)), 64)
+		if err != nil {
+			return fmt.Errorf("value must be a number for %s validation", name)
+		}
+
+		if !ok(val, bound) {
+			return fmt.Errorf("value must be %s %s", relation, param)
+		}
+		return nil
+	}
+}
+
+// allowLists caches the files read by the in_file validator, keyed by path
+// and reloaded when the modification time changes
+var allowLists = struct {
+	sync.Mutex
+	entries map[string]allowList
+}{entries: make(map[string]allowList)}
+
+type allowList struct {
+	modTime time.Time
+	values  map[string]bool
+}
+
+// readAllowList returns the non-empty, trimmed lines of the file at path.
+// Lines starting with # are comments.
+func readAllowList(path string) (map[string]bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed values: %w", err)
+	}
+
+	allowLists.Lock()
+	defer allowLists.Unlock()
+
+	if cached, ok := allowLists.entries[path]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.values, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed values: %w", err)
+	}
+	values := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			values[line] = true
+		}
+	}
+
+	allowLists.entries[path] = allowList{modTime: info.ModTime(), values: values}
+	return values, nil
+}
+
+// isEmptyValue reports whether v is its zero value or an empty collection
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// siblingField looks up another field of the struct being validated by
+// its Go name or, failing that, its cfg key
+func siblingField(ctx fieldContext, name string) (reflect.Value, error) {
+	if !ctx.parent.IsValid() {
+		return reflect.Value{}, fmt.Errorf("no sibling fields to compare with '%s'", name)
+	}
+	if other := ctx.parent.FieldByName(name); other.IsValid() {
+		return other, nil
+	}
+	t := ctx.parent.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("cfg"), ","); key == name {
+			return ctx.parent.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown field '%s'", name)
+}
+
+// fieldOrdering builds a validator comparing a field with the sibling
+// named by its parameter, passing ok the sign of field minus sibling
+func fieldOrdering(relation string, ok func(c int) bool) contextValidatorFunc {
+	return func(ctx fieldContext, value interface{}, param string) error {
+		other, err := siblingField(ctx, param)
+		if err != nil {
+			return err
+		}
+		if isEmptyValue(ctx.field) || isEmptyValue(other) {
+			return nil
+		}
+		c, err := compareFields(ctx.field, other)
+		if err != nil {
+			return err
+		}
+		if !ok(c) {
+			return fmt.Errorf("%s (%v) must be %s %s (%v)", ctx.name, ctx.field.Interface(), relation, param, other.Interface())
+		}
+		return nil
+	}
+}
+
+// compareFields orders two populated fields: integers (including
+// durations) exactly, other numbers as floats and strings as durations
+// or numbers
+func compareFields(a, b reflect.Value) (int, error) {
+	if isIntKind(a.Kind()) && isIntKind(b.Kind()) {
+		return cmp.Compare(a.Int(), b.Int()), nil
+	}
+	x, err1 := fieldNumber(a)
+	y, err2 := fieldNumber(b)
+	if err := errors.Join(err1, err2); err != nil {
+		return 0, err
+	}
+	return cmp.Compare(x, y), nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+// fieldNumber converts a numeric, duration or numeric string field to a
+// float64 for comparison
+func fieldNumber(v reflect.Value) (float64, error) {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int()), nil
+	case isUintKind(v.Kind()):
+		return float64(v.Uint()), nil
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), nil
+	case v.Kind() == reflect.String:
+		if d, err := time.ParseDuration(v.String()); err == nil {
+			return float64(d), nil
+		}
+		if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s value %v", v.Kind(), v.Interface())
+}
+
+// Built-in validators that need the field context
+func getContextValidators() map[string]contextValidatorFunc {
+	return map[string]contextValidatorFunc{
+		// required checks the populated field, so a zero number, false
+		// bool or empty list counts as missing. Use a pointer field to
+		// accept an explicit zero value.
+		"required": func(ctx fieldContext, value interface{}, param string) error {
+			if isEmptyValue(ctx.field) {
+				return fmt.Errorf("field is required")
+			}
+			return nil
+		},
+		"required_with": func(ctx fieldContext, value interface{}, param string) error {
+			other, err := siblingField(ctx, param)
+			if err != nil {
+				return err
+			}
+			if !isEmptyValue(other) && isEmptyValue(ctx.field) {
+				return fmt.Errorf("field is required when %s is set", param)
+			}
+			return nil
+		},
+		"required_without": func(ctx fieldContext, value interface{}, param string) error {
+			other, err := siblingField(ctx, param)
+			if err != nil {
+				return err
+			}
+			if isEmptyValue(other) && isEmptyValue(ctx.field) {
+				return fmt.Errorf("field is required when %s is not set", param)
+			}
+			return nil
+		},
+		// eqfield requires the field to equal a sibling, e.g. a password
+		// and its confirmation
+		"eqfield": func(ctx fieldContext, value interface{}, param string) error {
+			other, err := siblingField(ctx, param)
+			if err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(ctx.field.Interface(), other.Interface()) {
+				return fmt.Errorf("%s does not match %s", ctx.name, param)
+			}
+			return nil
+		},
+		// samelen requires a list to be as long as a sibling list, e.g.
+		// names and the weights paired with them
+		"samelen": func(ctx fieldContext, value interface{}, param string) error {
+			other, err := siblingField(ctx, param)
+			if err != nil {
+				return err
+			}
+			for _, v := range []reflect.Value{ctx.field, other} {
+				switch v.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+				default:
+					return fmt.Errorf("samelen requires lists, got %s", v.Kind())
+				}
+			}
+			if ctx.field.Len() != other.Len() {
+				return fmt.Errorf("length %d does not match %s length %d", ctx.field.Len(), param, other.Len())
+			}
+			return nil
+		},
+		// ltefield and gtefield order a field against a sibling, e.g. a
+		// min_timeout and max_timeout. Unset fields are not compared.
+		"ltefield": fieldOrdering("less than or equal to", func(c int) bool { return c <= 0 }),
+		"gtefield": fieldOrdering("greater than or equal to", func(c int) bool { return c >= 0 }),
+		// range compares in the domain of the field's kind, so int64 and
+		// uint64 fields keep their full width and floats keep fractions
+		"range": func(ctx fieldContext, value interface{}, param string) error {
+			parts := strings.Split(param, ",")
+			if len(parts) != 2 {
+				return fmt.Errorf("range validator requires min,max parameters")
+			}
+			lo, hi := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			str := stripNumericSeparators(fmt.Sprintf("%v", value))
+			if f, ok := value.(float64); ok && f == math.Trunc(f) {
+				str = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+
+			kind := reflect.Int64
+			if ctx.field.IsValid() {
+				t := ctx.field.Type()
+				for t.Kind() == reflect.Ptr {
+					t = t.Elem()
+				}
+				kind = t.Kind()
+			}
+
+			switch kind {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				min, err1 := strconv.ParseUint(lo, 10, 64)
+				max, err2 := strconv.ParseUint(hi, 10, 64)
+				if err1 != nil || err2 != nil {
+					return fmt.Errorf("range parameters must be unsigned integers")
+				}
+				val, err := strconv.ParseUint(str, 10, 64)
+				if err != nil {
+					return fmt.Errorf("value must be an unsigned integer for range validation")
+				}
+				if val < min || val > max {
+					return fmt.Errorf("value must be between %d and %d", min, max)
+				}
+			case reflect.Float32, reflect.Float64:
+				min, err1 := strconv.ParseFloat(lo, 64)
+				max, err2 := strconv.ParseFloat(hi, 64)
+				if err1 != nil || err2 != nil {
+					return fmt.Errorf("range parameters must be numbers")
+				}
+				val, err := strconv.ParseFloat(str, 64)
+				if err != nil {
+					return fmt.Errorf("value must be a number for range validation")
+				}
+				if val < min || val > max {
+					return fmt.Errorf("value must be between %s and %s", lo, hi)
+				}
+			default:
+				min, err1 := strconv.ParseInt(lo, 10, 64)
+				max, err2 := strconv.ParseInt(hi, 10, 64)
+				if err1 != nil || err2 != nil {
+					return fmt.Errorf("range parameters must be integers")
+				}
+				val, err := strconv.ParseInt(str, 10, 64)
+				if err != nil {
+					return fmt.Errorf("value must be an integer for range validation")
+				}
+				if val < min || val > max {
+					return fmt.Errorf("value must be between %d and %d", min, max)
+				}
+			}
+			return nil
+		},
+		// in_keys checks membership in a list held by another config key.
+		// The list key must be present in the merged sources; defaults
+		// of other fields are not visible here.
+		"in_keys": func(ctx fieldContext, value interface{}, param string) error {
+			list, ok := ctx.data[param]
+			if !ok {
+				return fmt.Errorf("allowed values key '%s' not found", param)
+			}
+
+			rv := reflect.ValueOf(list)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return fmt.Errorf("allowed values key '%s' is not a list", param)
+			}
+
+			str := fmt.Sprintf("%v", value)
+			for i := 0; i < rv.Len(); i++ {
+				if fmt.Sprintf("%v", rv.Index(i).Interface()) == str {
+					return nil
+				}
+			}
+			return fmt.Errorf("value must be one of the values in '%s'", param)
+		},
+	}
+}
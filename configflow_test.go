@@ -1,239 +1,3265 @@
-package configflow
-
-import (
-	"os"
-	"testing"
-)
-
-func TestBasicLoading(t *testing.T) {
-	type Config struct {
-		Port    int    `cfg:"port" default:"8080"`
-		AppName string `cfg:"app.name" default:"TestApp"`
-		Debug   bool   `cfg:"debug" default:"false"`
-	}
-
-	config := &Config{}
-	loader := New().AddMap(map[string]interface{}{
-		"port":     3000,
-		"app.name": "MyApp",
-		"debug":    true,
-	})
-
-	err := loader.Load(config)
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	if config.Port != 3000 {
-		t.Errorf("Expected port 3000, got %d", config.Port)
-	}
-	if config.AppName != "MyApp" {
-		t.Errorf("Expected app name 'MyApp', got %s", config.AppName)
-	}
-	if !config.Debug {
-		t.Errorf("Expected debug true, got %t", config.Debug)
-	}
-}
-
-func TestEnvironmentOverride(t *testing.T) {
-	type Config struct {
-		Port int `cfg:"port" env:"TEST_PORT" default:"8080"`
-	}
-
-	// Set environment variable
-	os.Setenv("TEST_PORT", "9090")
-	defer os.Unsetenv("TEST_PORT")
-
-	config := &Config{}
-	loader := New().
-		AddMap(map[string]interface{}{"port": 3000}).
-		AddEnv()
-
-	err := loader.Load(config)
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	if config.Port != 9090 {
-		t.Errorf("Expected port 9090 from env, got %d", config.Port)
-	}
-}
-
-func TestValidation(t *testing.T) {
-	type Config struct {
-		Port  int    `cfg:"port" validate:"range:1000,9999"`
-		Email string `cfg:"email" validate:"required,email"`
-	}
-
-	tests := []struct {
-		name      string
-		data      map[string]interface{}
-		expectErr bool
-	}{
-		{
-			name: "valid config",
-			data: map[string]interface{}{
-				"port":  8080,
-				"email": "test@example.com",
-			},
-			expectErr: false,
-		},
-		{
-			name: "invalid port range",
-			data: map[string]interface{}{
-				"port":  500,
-				"email": "test@example.com",
-			},
-			expectErr: true,
-		},
-		{
-			name: "invalid email",
-			data: map[string]interface{}{
-				"port":  8080,
-				"email": "invalid-email",
-			},
-			expectErr: true,
-		},
-		{
-			name: "missing required field",
-			data: map[string]interface{}{
-				"port": 8080,
-			},
-			expectErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			config := &Config{}
-			loader := New().AddMap(tt.data).EnableValidation()
-			err := loader.Load(config)
-
-			if tt.expectErr && err == nil {
-				t.Error("Expected error but got none")
-			}
-			if !tt.expectErr && err != nil {
-				t.Errorf("Expected no error but got: %v", err)
-			}
-		})
-	}
-}
-
-func TestCustomValidator(t *testing.T) {
-	type Config struct {
-		Status string `cfg:"status" validate:"custom_status"`
-	}
-
-	loader := New().AddValidator("custom_status", func(value interface{}, param string) error {
-		status := value.(string)
-		if status != "active" && status != "inactive" {
-			return ValidationError{
-				Field:   "status",
-				Value:   value,
-				Rule:    "custom_status",
-				Message: "status must be 'active' or 'inactive'",
-			}
-		}
-		return nil
-	})
-
-	// Test valid status
-	config := &Config{}
-	err := loader.AddMap(map[string]interface{}{"status": "active"}).Load(config)
-	if err != nil {
-		t.Errorf("Expected no error for valid status, got: %v", err)
-	}
-
-	// Test invalid status
-	config = &Config{}
-	err = loader.AddMap(map[string]interface{}{"status": "unknown"}).Load(config)
-	if err == nil {
-		t.Error("Expected error for invalid status")
-	}
-}
-
-func TestDefaultValues(t *testing.T) {
-	type Config struct {
-		Port    int    `cfg:"port" default:"8080"`
-		AppName string `cfg:"app.name" default:"DefaultApp"`
-		Debug   bool   `cfg:"debug" default:"true"`
-	}
-
-	config := &Config{}
-	loader := New() // No sources, should use defaults
-
-	err := loader.Load(config)
-	if err != nil {
-		t.Fatalf("Failed to load config with defaults: %v", err)
-	}
-
-	if config.Port != 8080 {
-		t.Errorf("Expected default port 8080, got %d", config.Port)
-	}
-	if config.AppName != "DefaultApp" {
-		t.Errorf("Expected default app name 'DefaultApp', got %s", config.AppName)
-	}
-	if !config.Debug {
-		t.Errorf("Expected default debug true, got %t", config.Debug)
-	}
-}
-
-func TestNestedConfig(t *testing.T) {
-	type DatabaseConfig struct {
-		URL      string `cfg:"database.url"`
-		MaxConns int    `cfg:"database.max_connections" default:"10"`
-	}
-
-	config := &DatabaseConfig{}
-	loader := New().AddMap(map[string]interface{}{
-		"database.url":             "postgres://localhost/test",
-		"database.max_connections": 20,
-	})
-
-	err := loader.Load(config)
-	if err != nil {
-		t.Fatalf("Failed to load nested config: %v", err)
-	}
-
-	if config.URL != "postgres://localhost/test" {
-		t.Errorf("Expected database URL, got %s", config.URL)
-	}
-	if config.MaxConns != 20 {
-		t.Errorf("Expected max connections 20, got %d", config.MaxConns)
-	}
-}
-
-func TestTypeConversion(t *testing.T) {
-	type Config struct {
-		Port    int     `cfg:"port"`
-		Rate    float64 `cfg:"rate"`
-		Enabled bool    `cfg:"enabled"`
-		Name    string  `cfg:"name"`
-	}
-
-	config := &Config{}
-	loader := New().AddMap(map[string]interface{}{
-		"port":    "8080",    // string to int
-		"rate":    "3.14",    // string to float
-		"enabled": "true",    // string to bool
-		"name":    123,       // int to string
-	})
-
-	err := loader.Load(config)
-	if err != nil {
-		t.Fatalf("Failed to load config with type conversion: %v", err)
-	}
-
-	if config.Port != 8080 {
-		t.Errorf("Expected port 8080, got %d", config.Port)
-	}
-	if config.Rate != 3.14 {
-		t.Errorf("Expected rate 3.14, got %f", config.Rate)
-	}
-	if !config.Enabled {
-		t.Errorf("Expected enabled true, got %t", config.Enabled)
-	}
-	if config.Name != "123" {
-		t.Errorf("Expected name '123', got %s", config.Name)
-	}
-}
\ No newline at end of file
+package configflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBasicLoading(t *testing.T) {
+	type Config struct {
+		Port    int    `cfg:"port" default:"8080"`
+		AppName string `cfg:"app.name" default:"TestApp"`
+		Debug   bool   `cfg:"debug" default:"false"`
+	}
+
+	config := &Config{}
+	loader := New().AddMap(map[string]interface{}{
+		"port":     3000,
+		"app.name": "MyApp",
+		"debug":    true,
+	})
+
+	err := loader.Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 3000 {
+		t.Errorf("Expected port 3000, got %d", config.Port)
+	}
+	if config.AppName != "MyApp" {
+		t.Errorf("Expected app name 'MyApp', got %s", config.AppName)
+	}
+	if !config.Debug {
+		t.Errorf("Expected debug true, got %t", config.Debug)
+	}
+}
+
+func TestEnvironmentOverride(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"port" env:"TEST_PORT" default:"8080"`
+	}
+
+	// Set environment variable
+	os.Setenv("TEST_PORT", "9090")
+	defer os.Unsetenv("TEST_PORT")
+
+	config := &Config{}
+	loader := New().
+		AddMap(map[string]interface{}{"port": 3000}).
+		AddEnv()
+
+	err := loader.Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected port 9090 from env, got %d", config.Port)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	type Config struct {
+		Port  int    `cfg:"port" validate:"range:1000,9999"`
+		Email string `cfg:"email" validate:"required,email"`
+	}
+
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "valid config",
+			data: map[string]interface{}{
+				"port":  8080,
+				"email": "test@example.com",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid port range",
+			data: map[string]interface{}{
+				"port":  500,
+				"email": "test@example.com",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid email",
+			data: map[string]interface{}{
+				"port":  8080,
+				"email": "invalid-email",
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing required field",
+			data: map[string]interface{}{
+				"port": 8080,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{}
+			loader := New().AddMap(tt.data).EnableValidation()
+			err := loader.Load(config)
+
+			if tt.expectErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestCustomValidator(t *testing.T) {
+	type Config struct {
+		Status string `cfg:"status" validate:"custom_status"`
+	}
+
+	loader := New().AddValidator("custom_status", func(value interface{}, param string) error {
+		status := value.(string)
+		if status != "active" && status != "inactive" {
+			return ValidationError{
+				Field:   "status",
+				Value:   value,
+				Rule:    "custom_status",
+				Message: "status must be 'active' or 'inactive'",
+			}
+		}
+		return nil
+	})
+
+	// Test valid status
+	config := &Config{}
+	err := loader.AddMap(map[string]interface{}{"status": "active"}).Load(config)
+	if err != nil {
+		t.Errorf("Expected no error for valid status, got: %v", err)
+	}
+
+	// Test invalid status
+	config = &Config{}
+	err = loader.AddMap(map[string]interface{}{"status": "unknown"}).Load(config)
+	if err == nil {
+		t.Error("Expected error for invalid status")
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	type Config struct {
+		Port    int    `cfg:"port" default:"8080"`
+		AppName string `cfg:"app.name" default:"DefaultApp"`
+		Debug   bool   `cfg:"debug" default:"true"`
+	}
+
+	config := &Config{}
+	loader := New() // No sources, should use defaults
+
+	err := loader.Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config with defaults: %v", err)
+	}
+
+	if config.Port != 8080 {
+		t.Errorf("Expected default port 8080, got %d", config.Port)
+	}
+	if config.AppName != "DefaultApp" {
+		t.Errorf("Expected default app name 'DefaultApp', got %s", config.AppName)
+	}
+	if !config.Debug {
+		t.Errorf("Expected default debug true, got %t", config.Debug)
+	}
+}
+
+func TestNestedConfig(t *testing.T) {
+	type DatabaseConfig struct {
+		URL      string `cfg:"database.url"`
+		MaxConns int    `cfg:"database.max_connections" default:"10"`
+	}
+
+	config := &DatabaseConfig{}
+	loader := New().AddMap(map[string]interface{}{
+		"database.url":             "postgres://localhost/test",
+		"database.max_connections": 20,
+	})
+
+	err := loader.Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load nested config: %v", err)
+	}
+
+	if config.URL != "postgres://localhost/test" {
+		t.Errorf("Expected database URL, got %s", config.URL)
+	}
+	if config.MaxConns != 20 {
+		t.Errorf("Expected max connections 20, got %d", config.MaxConns)
+	}
+}
+
+func TestTypeConversion(t *testing.T) {
+	type Config struct {
+		Port    int     `cfg:"port"`
+		Rate    float64 `cfg:"rate"`
+		Enabled bool    `cfg:"enabled"`
+		Name    string  `cfg:"name"`
+	}
+
+	config := &Config{}
+	loader := New().AddMap(map[string]interface{}{
+		"port":    "8080", // string to int
+		"rate":    "3.14", // string to float
+		"enabled": "true", // string to bool
+		"name":    123,    // int to string
+	})
+
+	err := loader.Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config with type conversion: %v", err)
+	}
+
+	if config.Port != 8080 {
+		t.Errorf("Expected port 8080, got %d", config.Port)
+	}
+	if config.Rate != 3.14 {
+		t.Errorf("Expected rate 3.14, got %f", config.Rate)
+	}
+	if !config.Enabled {
+		t.Errorf("Expected enabled true, got %t", config.Enabled)
+	}
+	if config.Name != "123" {
+		t.Errorf("Expected name '123', got %s", config.Name)
+	}
+}
+
+func TestKindAwareDefaults(t *testing.T) {
+	type Config struct {
+		Limits map[string]int    `cfg:"limits" default:"{\"a\":1,\"b\":2}"`
+		Labels map[string]string `cfg:"labels" default-json:"{\"team\":\"core\"}"`
+		Hosts  []string          `cfg:"hosts" default:"a.example.com, b.example.com"`
+		DSN    string            `cfg:"dsn" default:"postgres://localhost:5432/db"`
+	}
+
+	config := &Config{}
+	if err := New().Load(config); err != nil {
+		t.Fatalf("Failed to load config with defaults: %v", err)
+	}
+
+	if len(config.Limits) != 2 || config.Limits["a"] != 1 || config.Limits["b"] != 2 {
+		t.Errorf("Expected limits map {a:1 b:2}, got %v", config.Limits)
+	}
+	if config.Labels["team"] != "core" {
+		t.Errorf("Expected labels from default-json, got %v", config.Labels)
+	}
+	if len(config.Hosts) != 2 || config.Hosts[1] != "b.example.com" {
+		t.Errorf("Expected two hosts, got %v", config.Hosts)
+	}
+	if config.DSN != "postgres://localhost:5432/db" {
+		t.Errorf("Expected DSN default, got %s", config.DSN)
+	}
+}
+
+func TestStrictTypesUnsupportedKind(t *testing.T) {
+	type Config struct {
+		Events chan string `cfg:"events"`
+	}
+
+	data := map[string]interface{}{"events": "queue"}
+
+	// Lenient by default
+	if err := New().AddMap(data).Load(&Config{}); err != nil {
+		t.Fatalf("Expected no error without strict types, got: %v", err)
+	}
+
+	err := New().AddMap(data).StrictTypes().Load(&Config{})
+	var kindErr *UnsupportedKindError
+	if !errors.As(err, &kindErr) {
+		t.Fatalf("Expected UnsupportedKindError, got: %v", err)
+	}
+	if kindErr.Field != "Events" || kindErr.Kind != reflect.Chan {
+		t.Errorf("Expected Events/chan, got %s/%s", kindErr.Field, kindErr.Kind)
+	}
+}
+
+func TestStrictTypesMapMismatch(t *testing.T) {
+	type Config struct {
+		Limits map[string]int `cfg:"limits"`
+		Codes  map[int]string `cfg:"codes"`
+	}
+
+	data := map[string]interface{}{"limits": 5}
+
+	config := &Config{}
+	if err := New().AddMap(data).Load(config); err != nil {
+		t.Fatalf("Expected no error without strict types, got: %v", err)
+	}
+	if len(config.Limits) != 0 {
+		t.Errorf("Expected scalar to be ignored, got %v", config.Limits)
+	}
+
+	err := New().AddMap(data).StrictTypes().Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "expected an object") {
+		t.Errorf("Expected type error for scalar map value, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{"codes": map[string]interface{}{"1": "one"}}).StrictTypes().Load(&Config{})
+	var kindErr *UnsupportedKindError
+	if !errors.As(err, &kindErr) || kindErr.Field != "Codes" {
+		t.Errorf("Expected UnsupportedKindError for Codes, got: %v", err)
+	}
+
+	type Named struct {
+		Names map[string]fmt.Stringer `cfg:"names"`
+	}
+	err = New().AddMap(map[string]interface{}{"names": map[string]interface{}{"a": "api"}}).StrictTypes().Load(&Named{})
+	if err == nil || !strings.Contains(err.Error(), "cannot assign string") {
+		t.Errorf("Expected type error for interface field, got: %v", err)
+	}
+}
+
+func TestReloadNoReload(t *testing.T) {
+	type Config struct {
+		Port     int    `cfg:"port,noreload"`
+		LogLevel string `cfg:"log.level"`
+	}
+
+	data := map[string]interface{}{"port": 8080, "log.level": "info"}
+	loader := New().AddMap(data)
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	data["port"] = 9090
+	data["log.level"] = "debug"
+
+	changed, err := loader.Reload(config)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	if config.Port != 8080 {
+		t.Errorf("Expected noreload port to stay 8080, got %d", config.Port)
+	}
+	if config.LogLevel != "debug" {
+		t.Errorf("Expected log level 'debug' after reload, got %s", config.LogLevel)
+	}
+
+	expected := []string{"Port (noreload)", "LogLevel"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected changed %v, got %v", expected, changed)
+	}
+}
+
+func TestReloadNestedNoReload(t *testing.T) {
+	type Database struct {
+		URL  string `cfg:"url,noreload"`
+		Pool int    `cfg:"pool"`
+	}
+	type Config struct {
+		Database Database `cfg:"database"`
+	}
+
+	data := map[string]interface{}{"database": map[string]interface{}{"url": "postgres://a", "pool": 5}}
+	loader := New().AddMap(data)
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	data["database"] = map[string]interface{}{"url": "postgres://b", "pool": 10}
+	changed, err := loader.Reload(config)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	if config.Database.URL != "postgres://a" {
+		t.Errorf("Expected nested noreload URL to stay, got %s", config.Database.URL)
+	}
+	if config.Database.Pool != 10 {
+		t.Errorf("Expected pool 10 after reload, got %d", config.Database.Pool)
+	}
+
+	expected := []string{"Database.URL (noreload)", "Database.Pool"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected changed %v, got %v", expected, changed)
+	}
+}
+
+func TestFileSecrets(t *testing.T) {
+	type Config struct {
+		Password string `cfg:"db_password"`
+	}
+
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	t.Setenv("DB_PASSWORD_FILE", path)
+
+	config := &Config{}
+	if err := New().AddEnv().EnableFileSecrets().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Password != "s3cret" {
+		t.Errorf("Expected password from secret file, got %q", config.Password)
+	}
+}
+
+func TestFileSecretsSkipUnrelatedFiles(t *testing.T) {
+	type Config struct {
+		Token string `cfg:"token"`
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("t0ken\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	t.Setenv("LOG_FILE", "/var/log/nonexistent/app.log")
+	t.Setenv("LEGACY_TOKEN_FILE", path)
+
+	config := &Config{}
+	err := New().
+		AddEnv().
+		EnableFileSecrets().
+		MapEnv(func(key, value string) (string, string, bool) {
+			if key == "LEGACY_TOKEN_FILE" {
+				return "TOKEN_FILE", value, true
+			}
+			return key, value, true
+		}).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Expected unreadable LOG_FILE to be skipped, got: %v", err)
+	}
+	if config.Token != "t0ken" {
+		t.Errorf("Expected token from the mapped secret file, got %q", config.Token)
+	}
+}
+
+func TestInKeysValidator(t *testing.T) {
+	type Config struct {
+		Region string `cfg:"region" validate:"in_keys:allowed_regions"`
+	}
+
+	allowed := []interface{}{"eu-west-1", "us-east-1"}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{
+		"allowed_regions": allowed,
+		"region":          "us-east-1",
+	}).Load(config)
+	if err != nil {
+		t.Errorf("Expected no error for allowed region, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{
+		"allowed_regions": allowed,
+		"region":          "ap-south-1",
+	}).Load(&Config{})
+	if err == nil {
+		t.Error("Expected error for region outside allowed list")
+	}
+}
+
+type poolConfig struct {
+	MinConns int `cfg:"pool.min_conns"`
+	MaxConns int `cfg:"pool.max_conns"`
+}
+
+func (p *poolConfig) Validate() error {
+	if p.MinConns > p.MaxConns {
+		return fmt.Errorf("min_conns (%d) exceeds max_conns (%d)", p.MinConns, p.MaxConns)
+	}
+	return nil
+}
+
+func TestStructValidateMethod(t *testing.T) {
+	config := &poolConfig{}
+	err := New().AddMap(map[string]interface{}{
+		"pool.min_conns": 2,
+		"pool.max_conns": 10,
+	}).Load(config)
+	if err != nil {
+		t.Errorf("Expected no error for valid pool, got: %v", err)
+	}
+
+	config = &poolConfig{}
+	err = New().AddMap(map[string]interface{}{
+		"pool.min_conns": 20,
+		"pool.max_conns": 10,
+	}).Load(config)
+	if err == nil || !strings.Contains(err.Error(), "exceeds max_conns") {
+		t.Errorf("Expected Validate error, got: %v", err)
+	}
+}
+
+func TestNestedStructValidateMethod(t *testing.T) {
+	type Config struct {
+		Pool poolConfig
+	}
+
+	config := &Config{Pool: poolConfig{MinConns: 5, MaxConns: 1}}
+	err := New().Load(config)
+	if err == nil || !strings.Contains(err.Error(), "Pool") {
+		t.Errorf("Expected nested Validate error naming Pool, got: %v", err)
+	}
+}
+
+type delayedSource struct {
+	delay time.Duration
+	data  map[string]interface{}
+}
+
+func (d *delayedSource) Priority() int { return 0 }
+
+func (d *delayedSource) Load() (map[string]interface{}, error) {
+	return d.LoadContext(context.Background())
+}
+
+func (d *delayedSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	select {
+	case <-time.After(d.delay):
+		return d.data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestParallelSources(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	loader := New().
+		AddSource(&delayedSource{delay: 200 * time.Millisecond, data: map[string]interface{}{"host": "slow", "port": 1}}).
+		AddSource(&delayedSource{delay: 200 * time.Millisecond, data: map[string]interface{}{"port": 2}}).
+		ParallelSources()
+
+	config := &Config{}
+	start := time.Now()
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed >= 350*time.Millisecond {
+		t.Errorf("Expected parallel load near 200ms, took %v", elapsed)
+	}
+	if config.Host != "slow" || config.Port != 2 {
+		t.Errorf("Expected host 'slow' and port 2 in source order, got %s/%d", config.Host, config.Port)
+	}
+}
+
+func TestParallelSourcesContextCancel(t *testing.T) {
+	loader := New().AddSource(&delayedSource{delay: time.Second}).ParallelSources()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := loader.LoadContext(ctx, &struct{}{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got: %v", err)
+	}
+
+	// No fetch is still running once the load has returned
+	slow := &lingeringSource{}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := New().AddSource(slow).ParallelSources().LoadContext(ctx, &struct{}{}); err == nil {
+		t.Error("Expected canceled load to fail")
+	}
+	if !slow.finished.Load() {
+		t.Error("Expected the load to wait for in-flight fetches")
+	}
+}
+
+// lingeringSource takes a while to return after its context is canceled
+type lingeringSource struct {
+	finished atomic.Bool
+}
+
+func (s *lingeringSource) Priority() int { return 0 }
+
+func (s *lingeringSource) Load() (map[string]interface{}, error) {
+	return s.LoadContext(context.Background())
+}
+
+func (s *lingeringSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	s.finished.Store(true)
+	return nil, ctx.Err()
+}
+
+type countingSource struct {
+	calls int
+	err   error
+}
+
+func (c *countingSource) Priority() int { return 0 }
+
+func (c *countingSource) Load() (map[string]interface{}, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return map[string]interface{}{"port": c.calls}, nil
+}
+
+func TestCachingSource(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"port"`
+	}
+
+	counter := &countingSource{}
+	loader := New().AddSource(NewCachingSource(counter, time.Hour))
+
+	for i := 0; i < 3; i++ {
+		config := &Config{}
+		if err := loader.Load(config); err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if config.Port != 1 {
+			t.Errorf("Expected cached port 1, got %d", config.Port)
+		}
+	}
+
+	if counter.calls != 1 {
+		t.Errorf("Expected source to be called once within TTL, got %d", counter.calls)
+	}
+}
+
+func TestCachingSourceServeStale(t *testing.T) {
+	counter := &countingSource{}
+	cache := NewCachingSource(counter, 0)
+	cache.ServeStale = true
+
+	if _, err := cache.Load(); err != nil {
+		t.Fatalf("Failed first load: %v", err)
+	}
+
+	counter.err = errors.New("config server down")
+	data, err := cache.Load()
+	if err != nil {
+		t.Fatalf("Expected stale data, got error: %v", err)
+	}
+	if data["port"] != 1 {
+		t.Errorf("Expected stale port 1, got %v", data["port"])
+	}
+}
+
+func TestScalarIntoSlice(t *testing.T) {
+	type Config struct {
+		Hosts []string `cfg:"hosts"`
+		Ports []int    `cfg:"ports"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "hosts: db.example.com\nports: [5432, 5433]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config := &Config{}
+	if err := New().AddFile(path).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !reflect.DeepEqual(config.Hosts, []string{"db.example.com"}) {
+		t.Errorf("Expected single-element hosts slice, got %v", config.Hosts)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{5432, 5433}) {
+		t.Errorf("Expected ports [5432 5433], got %v", config.Ports)
+	}
+}
+
+func TestUniqueAndSortedValidators(t *testing.T) {
+	type Config struct {
+		Hosts []string `cfg:"hosts" validate:"unique"`
+		Ports []int    `cfg:"ports" validate:"sorted"`
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "valid lists",
+			data: map[string]interface{}{
+				"hosts": []interface{}{"a", "b"},
+				"ports": []interface{}{80, 443, 8080},
+			},
+		},
+		{
+			name: "duplicate host",
+			data: map[string]interface{}{
+				"hosts": []interface{}{"a", "b", "a"},
+			},
+			wantErr: "duplicate value 'a' at index 2",
+		},
+		{
+			name: "out of order ports",
+			data: map[string]interface{}{
+				"ports": []interface{}{80, 8080, 443},
+			},
+			wantErr: "value '443' at index 2 is out of order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().AddMap(tt.data).Load(&Config{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBase64Option(t *testing.T) {
+	type Config struct {
+		Key   []byte `cfg:"key,base64"`
+		Token string `cfg:"token,base64"`
+	}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{
+		"key":   "AAEC/w==",
+		"token": "c2VjcmV0",
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !bytes.Equal(config.Key, []byte{0x00, 0x01, 0x02, 0xff}) {
+		t.Errorf("Expected decoded key bytes, got %v", config.Key)
+	}
+	if config.Token != "secret" {
+		t.Errorf("Expected decoded token 'secret', got %q", config.Token)
+	}
+
+	err = New().AddMap(map[string]interface{}{"key": "not base64!"}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "failed to decode base64 for field Key") {
+		t.Errorf("Expected base64 decode error, got: %v", err)
+	}
+}
+
+func TestByteSliceFromString(t *testing.T) {
+	type Config struct {
+		Greeting []byte `cfg:"greeting"`
+	}
+
+	config := &Config{}
+	if err := New().AddMap(map[string]interface{}{"greeting": "hello"}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !bytes.Equal(config.Greeting, []byte("hello")) {
+		t.Errorf("Expected bytes of 'hello', got %v", config.Greeting)
+	}
+}
+
+func TestStrictTypesLossyConversion(t *testing.T) {
+	type Config struct {
+		Workers int `cfg:"workers"`
+	}
+
+	data := map[string]interface{}{"workers": 3.9}
+
+	err := New().AddMap(data).StrictTypes().Load(&Config{})
+	var lossyErr *LossyConversionError
+	if !errors.As(err, &lossyErr) {
+		t.Fatalf("Expected LossyConversionError, got: %v", err)
+	}
+	if lossyErr.Field != "Workers" {
+		t.Errorf("Expected field Workers, got %s", lossyErr.Field)
+	}
+
+	// Whole floats (as decoded from JSON) still convert
+	config := &Config{}
+	if err := New().AddMap(map[string]interface{}{"workers": 4.0}).StrictTypes().Load(config); err != nil {
+		t.Fatalf("Expected whole float to convert, got: %v", err)
+	}
+	if config.Workers != 4 {
+		t.Errorf("Expected 4 workers, got %d", config.Workers)
+	}
+}
+
+func TestWholeFloatsToIntegers(t *testing.T) {
+	type Config struct {
+		Events int64  `cfg:"events"`
+		Limit  uint32 `cfg:"limit"`
+		Small  int8   `cfg:"small"`
+	}
+
+	config := &Config{}
+	if err := New().AddJSON(`{"events": 1e6, "limit": 2.5e3}`).StrictTypes().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Events != 1000000 || config.Limit != 2500 {
+		t.Errorf("Expected 1000000 and 2500, got %d and %d", config.Events, config.Limit)
+	}
+
+	err := New().AddJSON(`{"small": 1e3}`).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("Expected overflow error, got: %v", err)
+	}
+}
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+)
+
+func TestRegisterEnum(t *testing.T) {
+	type Config struct {
+		Level logLevel `cfg:"level"`
+	}
+
+	loader := New().
+		RegisterEnum(reflect.TypeOf(logLevel(0)), map[string]int64{
+			"debug": int64(levelDebug),
+			"info":  int64(levelInfo),
+			"warn":  int64(levelWarn),
+		}).
+		AddMap(map[string]interface{}{"level": "warn"})
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Level != levelWarn {
+		t.Errorf("Expected level warn (2), got %d", config.Level)
+	}
+
+	err := loader.AddMap(map[string]interface{}{"level": "loud"}).Load(&Config{})
+	if err == nil {
+		t.Error("Expected error for unknown enum name")
+	}
+}
+
+type colorCode uint8
+
+func TestRegisterEnumUnsigned(t *testing.T) {
+	type Config struct {
+		Color colorCode `cfg:"color"`
+	}
+
+	values := map[string]int64{"red": 1, "green": 2, "huge": 300}
+	loader := New().RegisterEnum(reflect.TypeOf(colorCode(0)), values)
+
+	config := &Config{}
+	if err := loader.AddMap(map[string]interface{}{"color": "green"}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Color != 2 {
+		t.Errorf("Expected color 2, got %d", config.Color)
+	}
+
+	err := New().RegisterEnum(reflect.TypeOf(colorCode(0)), values).
+		AddMap(map[string]interface{}{"color": "huge"}).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("Expected overflow error, got: %v", err)
+	}
+
+	err = New().RegisterEnum(reflect.TypeOf(""), values).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "not an integer type") {
+		t.Errorf("Expected error for non-integer enum type, got: %v", err)
+	}
+}
+
+func TestProfileFiles(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":            "host: localhost\nport: 8080\n",
+		"config.production.yaml": "host: prod.example.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	t.Setenv("APP_ENV", "production")
+
+	config := &Config{}
+	if err := New().AddProfileFiles(dir, "config.yaml", "APP_ENV").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Host != "prod.example.com" {
+		t.Errorf("Expected host from production overlay, got %s", config.Host)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected port 8080 from base file, got %d", config.Port)
+	}
+
+	// A profile without an overlay file falls back to the base file
+	t.Setenv("APP_ENV", "staging")
+	config = &Config{}
+	if err := New().AddProfileFiles(dir, "config.yaml", "APP_ENV").Load(config); err != nil {
+		t.Fatalf("Expected missing profile file to be ignored, got: %v", err)
+	}
+	if config.Host != "localhost" {
+		t.Errorf("Expected base host, got %s", config.Host)
+	}
+}
+
+func TestLengthValidatorsRunesVsBytes(t *testing.T) {
+	type RuneConfig struct {
+		Name string `cfg:"name" validate:"maxlen:5:runes"`
+	}
+	type ByteConfig struct {
+		Name string `cfg:"name" validate:"maxlen:5:bytes"`
+	}
+
+	// 5 runes, 10 bytes
+	data := map[string]interface{}{"name": "ñáéíó"}
+
+	if err := New().AddMap(data).Load(&RuneConfig{}); err != nil {
+		t.Errorf("Expected rune length check to pass, got: %v", err)
+	}
+
+	err := New().AddMap(data).Load(&ByteConfig{})
+	if err == nil || !strings.Contains(err.Error(), "at most 5 bytes") {
+		t.Errorf("Expected byte length check to fail, got: %v", err)
+	}
+}
+
+func TestOptionalPointerFields(t *testing.T) {
+	type Config struct {
+		Debug   *bool   `cfg:"debug"`
+		Retries *int    `cfg:"retries"`
+		Name    *string `cfg:"name"`
+	}
+
+	config := &Config{}
+	if err := New().AddMap(map[string]interface{}{"retries": 3}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Debug != nil {
+		t.Errorf("Expected Debug to stay nil when absent, got %v", *config.Debug)
+	}
+	if config.Name != nil {
+		t.Errorf("Expected Name to stay nil when absent, got %q", *config.Name)
+	}
+	if config.Retries == nil || *config.Retries != 3 {
+		t.Errorf("Expected Retries to point to 3, got %v", config.Retries)
+	}
+
+	config = &Config{}
+	if err := New().AddMap(map[string]interface{}{"debug": false}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Debug == nil || *config.Debug {
+		t.Errorf("Expected Debug to point to false, got %v", config.Debug)
+	}
+}
+
+func TestAddYAMLAndJSON(t *testing.T) {
+	type Config struct {
+		URL      string `cfg:"database.url"`
+		MaxConns int    `cfg:"database.pool.max_conns"`
+		Debug    bool   `cfg:"debug"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddYAML(`
+database:
+  url: postgres://localhost/app
+  pool:
+    max_conns: 10
+`).
+		AddJSON(`{"debug": true}`).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load inline config: %v", err)
+	}
+
+	if config.URL != "postgres://localhost/app" {
+		t.Errorf("Expected database URL, got %s", config.URL)
+	}
+	if config.MaxConns != 10 {
+		t.Errorf("Expected max conns 10, got %d", config.MaxConns)
+	}
+	if !config.Debug {
+		t.Error("Expected debug true from inline JSON")
+	}
+}
+
+func TestRequiredWithAndWithout(t *testing.T) {
+	type Config struct {
+		CertFile string `cfg:"tls.cert" validate:"required_with:KeyFile"`
+		KeyFile  string `cfg:"tls.key"`
+		Password string `cfg:"auth.password" validate:"required_without:Token"`
+		Token    string `cfg:"auth.token"`
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "with: both set",
+			data: map[string]interface{}{"tls.cert": "c.pem", "tls.key": "k.pem", "auth.token": "t"},
+		},
+		{
+			name:    "with: other set, field missing",
+			data:    map[string]interface{}{"tls.key": "k.pem", "auth.token": "t"},
+			wantErr: "field 'CertFile': field is required when KeyFile is set",
+		},
+		{
+			name: "with: neither set",
+			data: map[string]interface{}{"auth.token": "t"},
+		},
+		{
+			name: "without: other absent, field set",
+			data: map[string]interface{}{"auth.password": "p"},
+		},
+		{
+			name:    "without: both absent",
+			data:    map[string]interface{}{},
+			wantErr: "field 'Password': field is required when Token is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().AddMap(tt.data).Load(&Config{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTransformValidator(t *testing.T) {
+	type Config struct {
+		Country string `cfg:"country" validate:"upper,country_code"`
+	}
+
+	loader := New().
+		AddTransformValidator("upper", TransformFunc(func(value interface{}) (interface{}, error) {
+			return strings.ToUpper(fmt.Sprintf("%v", value)), nil
+		})).
+		AddValidator("country_code", func(value interface{}, param string) error {
+			if value != "DE" && value != "US" {
+				return fmt.Errorf("unknown country code")
+			}
+			return nil
+		}).
+		AddMap(map[string]interface{}{"country": "de"})
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Country != "DE" {
+		t.Errorf("Expected transformed country 'DE', got %s", config.Country)
+	}
+}
+
+func TestAddFileAs(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"server.port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.conf")
+	if err := os.WriteFile(path, []byte("server:\n  port: 7070\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if err := New().AddFile(path).Load(&Config{}); err == nil {
+		t.Error("Expected unknown .conf extension to error without an override")
+	}
+
+	config := &Config{}
+	if err := New().AddFileAs(path, "yaml").Load(config); err != nil {
+		t.Fatalf("Failed to load .conf as YAML: %v", err)
+	}
+	if config.Port != 7070 {
+		t.Errorf("Expected port 7070, got %d", config.Port)
+	}
+}
+
+func TestVerboseErrors(t *testing.T) {
+	type Config struct {
+		Port     int    `cfg:"port" validate:"range:1000,9999"`
+		Password string `cfg:"password,secret" validate:"minlen:8"`
+	}
+
+	data := map[string]interface{}{"port": 80}
+
+	err := New().AddMap(data).Load(&Config{})
+	if err == nil || strings.Contains(err.Error(), "80") {
+		t.Errorf("Expected concise error without value, got: %v", err)
+	}
+
+	err = New().AddMap(data).VerboseErrors().Load(&Config{})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, want := range []string{"rule 'range:1000,9999'", "value '80'"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected verbose error to contain %q, got: %v", want, err)
+		}
+	}
+
+	data = map[string]interface{}{"port": 8080, "password": "hunter2"}
+	err = New().AddMap(data).VerboseErrors().Load(&Config{})
+	if err == nil || strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "******") {
+		t.Errorf("Expected secret value to be masked, got: %v", err)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	type Config struct {
+		Port   int            `cfg:"port"`
+		Limits map[string]int `cfg:"limits"`
+	}
+
+	data := map[string]interface{}{
+		"port":       8080,
+		"limits.rps": 10,
+		"prot":       9090,
+		"debug":      true,
+	}
+
+	var seen []string
+	loader := New().AddMap(data).OnUnknownKey(func(key string, value interface{}) {
+		seen = append(seen, fmt.Sprintf("%s=%v", key, value))
+	})
+	if err := loader.Load(&Config{}); err != nil {
+		t.Fatalf("Expected no error without strict mode, got: %v", err)
+	}
+	if !reflect.DeepEqual(seen, []string{"debug=true", "prot=9090"}) {
+		t.Errorf("Expected callback for debug and prot, got %v", seen)
+	}
+
+	seen = nil
+	err := loader.Strict().Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "debug, prot") {
+		t.Errorf("Expected strict error listing unknown keys, got: %v", err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("Expected callback to fire before strict error, got %v", seen)
+	}
+}
+
+func TestDurationSlicesAndMaps(t *testing.T) {
+	type Config struct {
+		Timeout  time.Duration            `cfg:"timeout"`
+		Backoff  []time.Duration          `cfg:"backoff"`
+		Timeouts map[string]time.Duration `cfg:"timeouts"`
+	}
+
+	config := &Config{}
+	err := New().AddYAML(`
+timeout: 30s
+backoff: ["1s", "2s"]
+timeouts:
+  read: 5s
+  write: 1m
+`).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Timeout != 30*time.Second {
+		t.Errorf("Expected timeout 30s, got %v", config.Timeout)
+	}
+	if !reflect.DeepEqual(config.Backoff, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Errorf("Expected backoff [1s 2s], got %v", config.Backoff)
+	}
+	expected := map[string]time.Duration{"read": 5 * time.Second, "write": time.Minute}
+	if !reflect.DeepEqual(config.Timeouts, expected) {
+		t.Errorf("Expected timeouts %v, got %v", expected, config.Timeouts)
+	}
+}
+
+func TestEnvConversionError(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"port" env:"PORT"`
+	}
+
+	t.Setenv("PORT", "abc")
+
+	err := New().AddEnv().Load(&Config{})
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("Expected ConversionError, got: %v", err)
+	}
+	if convErr.Variable != "PORT" || convErr.Type.Kind() != reflect.Int {
+		t.Errorf("Expected PORT/int, got %s/%s", convErr.Variable, convErr.Type)
+	}
+	if !strings.Contains(err.Error(), "PORT") {
+		t.Errorf("Expected error to name PORT, got: %v", err)
+	}
+
+	// File-sourced failures keep the generic error
+	err = New().AddMap(map[string]interface{}{"port": "abc"}).Load(&Config{})
+	if err == nil || errors.As(err, &convErr) {
+		t.Errorf("Expected non-env conversion failure, got: %v", err)
+	}
+}
+
+func TestConversionErrorNamesRealVariable(t *testing.T) {
+	type Config struct {
+		Port    int `cfg:"server.port"`
+		Retries int `cfg:"retries"`
+	}
+
+	t.Setenv("APP_PORT", "abc")
+	err := New().BindEnv("server.port", "APP_PORT").Load(&Config{})
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Variable != "APP_PORT" {
+		t.Errorf("Expected ConversionError for APP_PORT, got: %v", err)
+	}
+
+	t.Setenv("LEGACY_RETRIES", "many")
+	err = New().
+		AddEnv().
+		MapEnv(func(key, value string) (string, string, bool) {
+			if key == "LEGACY_RETRIES" {
+				return "retries", value, true
+			}
+			return key, value, true
+		}).
+		Load(&Config{})
+	if !errors.As(err, &convErr) || convErr.Variable != "LEGACY_RETRIES" {
+		t.Errorf("Expected ConversionError for LEGACY_RETRIES, got: %v", err)
+	}
+}
+
+type storage interface {
+	Describe() string
+}
+
+type s3Storage struct {
+	Bucket string `cfg:"bucket" validate:"required"`
+	Region string `cfg:"region" default:"us-east-1"`
+}
+
+func (s *s3Storage) Describe() string { return "s3://" + s.Bucket + " in " + s.Region }
+
+type localStorage struct {
+	Path string `cfg:"path"`
+}
+
+func (s localStorage) Describe() string { return "file://" + s.Path }
+
+func TestRegisterVariant(t *testing.T) {
+	type Config struct {
+		Storage storage `cfg:"storage"`
+	}
+
+	newLoader := func(data map[string]interface{}) *Loader {
+		return New().
+			RegisterVariant("storage.type", "s3", &s3Storage{}).
+			RegisterVariant("storage.type", "local", localStorage{}).
+			AddMap(data)
+	}
+
+	config := &Config{}
+	err := newLoader(map[string]interface{}{
+		"storage": map[string]interface{}{"type": "s3", "bucket": "assets"},
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load s3 variant: %v", err)
+	}
+	if got := config.Storage.Describe(); got != "s3://assets in us-east-1" {
+		t.Errorf("Expected s3 storage, got %s", got)
+	}
+
+	config = &Config{}
+	err = newLoader(map[string]interface{}{
+		"storage": map[string]interface{}{"type": "local", "path": "/var/data"},
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load local variant: %v", err)
+	}
+	if got := config.Storage.Describe(); got != "file:///var/data" {
+		t.Errorf("Expected local storage, got %s", got)
+	}
+
+	err = newLoader(map[string]interface{}{
+		"storage": map[string]interface{}{"type": "gcs"},
+	}).Load(&Config{})
+	if err == nil {
+		t.Error("Expected error for unregistered variant")
+	}
+}
+
+func TestRequiredIsTypeAware(t *testing.T) {
+	type Config struct {
+		Workers int      `cfg:"workers" validate:"required"`
+		Hosts   []string `cfg:"hosts" validate:"required"`
+		Enabled bool     `cfg:"enabled" validate:"required"`
+		Verbose *bool    `cfg:"verbose" validate:"required"`
+	}
+
+	valid := map[string]interface{}{
+		"workers": 4,
+		"hosts":   []interface{}{"a"},
+		"enabled": true,
+		"verbose": false,
+	}
+
+	if err := New().AddMap(valid).Load(&Config{}); err != nil {
+		t.Fatalf("Expected valid config to pass, got: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		field string
+	}{
+		{name: "zero int", key: "workers", value: 0, field: "Workers"},
+		{name: "empty slice", key: "hosts", value: []interface{}{}, field: "Hosts"},
+		{name: "false bool", key: "enabled", value: false, field: "Enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make(map[string]interface{}, len(valid))
+			for k, v := range valid {
+				data[k] = v
+			}
+			data[tt.key] = tt.value
+
+			err := New().AddMap(data).Load(&Config{})
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("Expected required error for %s, got: %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestBindEnvAndGet(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://prod/app")
+
+	loader := New().
+		AddMap(map[string]interface{}{"database.url": "postgres://localhost/app", "port": 8080}).
+		BindEnv("database.url", "DATABASE_URL")
+
+	nested, err := loader.LoadMap()
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+
+	value, ok := loader.Get("database.url")
+	if !ok || value != "postgres://prod/app" {
+		t.Errorf("Expected bound env value from Get, got %v (found %t)", value, ok)
+	}
+	if db, _ := nested["database"].(map[string]interface{}); db["url"] != "postgres://prod/app" {
+		t.Errorf("Expected nested database.url from env, got %v", nested["database"])
+	}
+	if _, ok := loader.Get("missing"); ok {
+		t.Error("Expected missing key to be absent")
+	}
+}
+
+func TestDefaultFunc(t *testing.T) {
+	type Config struct {
+		Hostname string `cfg:"hostname"`
+		Region   string `cfg:"region" default:"eu-west-1"`
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("Hostname unavailable: %v", err)
+	}
+
+	called := false
+	loader := New().
+		DefaultFunc("hostname", func() interface{} {
+			h, _ := os.Hostname()
+			return h
+		}).
+		DefaultFunc("region", func() interface{} {
+			called = true
+			return "computed"
+		})
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Hostname != hostname {
+		t.Errorf("Expected hostname %q, got %q", hostname, config.Hostname)
+	}
+	if config.Region != "eu-west-1" || called {
+		t.Errorf("Expected static default to win over default func, got %q", config.Region)
+	}
+
+	config = &Config{}
+	if err := loader.AddMap(map[string]interface{}{"hostname": "web-1"}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Hostname != "web-1" {
+		t.Errorf("Expected source value to win over default func, got %q", config.Hostname)
+	}
+}
+
+func TestCollectErrorsAllRules(t *testing.T) {
+	type Config struct {
+		Code  string `cfg:"code" validate:"minlen:3,email"`
+		Port  int    `cfg:"port" validate:"range:1000,9999"`
+		Owner string `cfg:"owner" validate:"required"`
+	}
+
+	data := map[string]interface{}{"code": "x", "port": 80}
+
+	// Collect mode reports the first failing rule of each field
+	err := New().AddMap(data).CollectErrors().Load(&Config{})
+	var failures ValidationErrors
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected ValidationErrors, got: %v", err)
+	}
+	if len(failures) != 3 {
+		t.Errorf("Expected one failure per field, got %d: %v", len(failures), err)
+	}
+
+	// With ReportAllRules both rules of Code are reported
+	err = New().AddMap(data).CollectErrors().ReportAllRules().Load(&Config{})
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected ValidationErrors, got: %v", err)
+	}
+	var codeRules []string
+	for _, f := range failures {
+		if f.Field == "Code" {
+			codeRules = append(codeRules, f.Rule)
+		}
+	}
+	if !reflect.DeepEqual(codeRules, []string{"minlen:3", "email"}) {
+		t.Errorf("Expected both Code rules reported, got %v", codeRules)
+	}
+
+	var single *ValidationError
+	if !errors.As(err, &single) {
+		t.Error("Expected errors.As to reach individual ValidationError")
+	}
+}
+
+func TestScope(t *testing.T) {
+	type DatabaseConfig struct {
+		URL      string `cfg:"url"`
+		MaxConns int    `cfg:"pool.max_conns" default:"5"`
+	}
+
+	loader := New().AddYAML(`
+port: 8080
+database:
+  url: postgres://localhost/app
+  pool:
+    max_conns: 20
+`)
+
+	db := &DatabaseConfig{}
+	if err := loader.Scope("database").Strict().Load(db); err != nil {
+		t.Fatalf("Failed to load scoped config: %v", err)
+	}
+
+	if db.URL != "postgres://localhost/app" {
+		t.Errorf("Expected scoped URL, got %s", db.URL)
+	}
+	if db.MaxConns != 20 {
+		t.Errorf("Expected scoped max conns 20, got %d", db.MaxConns)
+	}
+}
+
+func TestOnValidationError(t *testing.T) {
+	type Config struct {
+		Port int    `cfg:"port" validate:"range:1000,9999"`
+		Name string `cfg:"name" validate:"minlen:3"`
+	}
+
+	loader := New().
+		AddMap(map[string]interface{}{"port": 80, "name": "ab"}).
+		OnValidationError(func(ve ValidationError) error {
+			if strings.HasPrefix(ve.Rule, "minlen") {
+				return nil // advisory only
+			}
+			return fmt.Errorf("%s: valeur hors limites", strings.ToLower(ve.Field))
+		})
+
+	err := loader.Load(&Config{})
+	if err == nil || err.Error() != "port: valeur hors limites" {
+		t.Errorf("Expected rewritten error, got: %v", err)
+	}
+
+	config := &Config{}
+	err = loader.AddMap(map[string]interface{}{"port": 8080}).Load(config)
+	if err != nil {
+		t.Errorf("Expected suppressed minlen failure, got: %v", err)
+	}
+	if config.Name != "ab" {
+		t.Errorf("Expected suppressed field to still be set, got %q", config.Name)
+	}
+}
+
+func TestNumericSeparators(t *testing.T) {
+	type Config struct {
+		MaxEvents int     `cfg:"max_events"`
+		Budget    float64 `cfg:"budget"`
+		Label     string  `cfg:"label"`
+	}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{
+		"max_events": "1_000_000",
+		"budget":     "2_500.75",
+		"label":      "1_000_000",
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.MaxEvents != 1000000 {
+		t.Errorf("Expected 1000000, got %d", config.MaxEvents)
+	}
+	if config.Budget != 2500.75 {
+		t.Errorf("Expected 2500.75, got %f", config.Budget)
+	}
+	if config.Label != "1_000_000" {
+		t.Errorf("Expected string field to keep underscores, got %s", config.Label)
+	}
+
+	for _, bad := range []string{"_100", "100_", "1__000"} {
+		if err := New().AddMap(map[string]interface{}{"max_events": bad}).Load(&Config{}); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestOneOfCaseInsensitive(t *testing.T) {
+	type Strict struct {
+		Level string `cfg:"level" validate:"oneof:debug info warn"`
+	}
+	type Relaxed struct {
+		Level string `cfg:"level" validate:"oneofci:debug info warn"`
+	}
+
+	data := map[string]interface{}{"level": "DEBUG"}
+
+	if err := New().AddMap(data).Load(&Strict{}); err == nil {
+		t.Error("Expected case-sensitive oneof to reject DEBUG")
+	}
+
+	config := &Relaxed{}
+	if err := New().AddMap(data).Load(config); err != nil {
+		t.Errorf("Expected oneofci to accept DEBUG, got: %v", err)
+	}
+	if config.Level != "DEBUG" {
+		t.Errorf("Expected value to be kept as given, got %s", config.Level)
+	}
+
+	if err := New().AddMap(map[string]interface{}{"level": "trace"}).Load(&Relaxed{}); err == nil {
+		t.Error("Expected oneofci to reject values outside the list")
+	}
+}
+
+func TestRemoteFuncSource(t *testing.T) {
+	type Config struct {
+		Region  string `cfg:"service.region"`
+		Retries int    `cfg:"service.retries"`
+	}
+
+	fetch := func(ctx context.Context) ([]byte, string, error) {
+		return []byte("service:\n  region: eu-west-1\n  retries: 3\n"), "yaml", nil
+	}
+
+	config := &Config{}
+	if err := New().AddRemoteFunc(fetch).Load(config); err != nil {
+		t.Fatalf("Failed to load remote config: %v", err)
+	}
+	if config.Region != "eu-west-1" || config.Retries != 3 {
+		t.Errorf("Expected eu-west-1/3, got %s/%d", config.Region, config.Retries)
+	}
+
+	failing := func(ctx context.Context) ([]byte, string, error) {
+		return nil, "", errors.New("unavailable")
+	}
+	if err := New().AddRemoteFunc(failing).Load(&Config{}); err == nil {
+		t.Error("Expected fetch error to propagate")
+	}
+}
+
+func TestRequireTags(t *testing.T) {
+	type Database struct {
+		URL  string `cfg:"url"`
+		Pool int
+	}
+	type Config struct {
+		Port     int `cfg:"port"`
+		Name     string
+		Cache    string   `cfg:"-"`
+		Database Database `cfg:"database"`
+	}
+
+	err := New().RequireTags().Load(&Config{})
+	if err == nil {
+		t.Fatal("Expected error for untagged fields")
+	}
+	if !strings.Contains(err.Error(), "Name, Database.Pool") {
+		t.Errorf("Expected untagged fields to be listed, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "Cache") {
+		t.Errorf("Expected cfg:\"-\" field to be exempt, got: %v", err)
+	}
+
+	config := &Config{Cache: "keep"}
+	err = New().AddMap(map[string]interface{}{"cache": "x"}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Cache != "keep" {
+		t.Errorf("Expected cfg:\"-\" field to be skipped, got %s", config.Cache)
+	}
+}
+
+func TestSkipTagIgnoresDashKey(t *testing.T) {
+	type Config struct {
+		Port   int    `cfg:"port"`
+		Cached string `cfg:"-" default:"fallback" validate:"required"`
+	}
+
+	config := &Config{Cached: "existing"}
+	err := New().AddMap(map[string]interface{}{
+		"port": 8080,
+		"-":    "from-data",
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Cached != "existing" {
+		t.Errorf("Expected cfg:\"-\" field to keep its value, got %s", config.Cached)
+	}
+
+	// Skipped fields are not validated either
+	if err := New().Load(&Config{}); err != nil {
+		t.Errorf("Expected skipped field to be exempt from validation, got: %v", err)
+	}
+
+	dumped, err := Dump(&Config{Port: 1, Cached: "x"})
+	if err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	if _, ok := dumped["-"]; ok {
+		t.Errorf("Expected cfg:\"-\" field to be left out of Dump, got %v", dumped)
+	}
+}
+
+type failingValidate struct {
+	Name string
+}
+
+func (f *failingValidate) Validate() error {
+	return fmt.Errorf("skipped validate ran")
+}
+
+func TestSkipTagSkipsNestedValidate(t *testing.T) {
+	type Config struct {
+		Port  int             `cfg:"port"`
+		Cache failingValidate `cfg:"-"`
+	}
+
+	if err := New().AddMap(map[string]interface{}{"port": 8080}).Load(&Config{}); err != nil {
+		t.Errorf("Expected cfg:\"-\" struct to be exempt from Validate, got: %v", err)
+	}
+}
+
+func TestMergeSlices(t *testing.T) {
+	type Config struct {
+		Plugins []string `cfg:"plugins,merge"`
+		Hosts   []string `cfg:"hosts"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddYAML("plugins: [a, b]\nhosts: [x, y]").
+		AddYAML("plugins: [c, a]\nhosts: [z]").
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !reflect.DeepEqual(config.Plugins, []string{"a", "b", "c"}) {
+		t.Errorf("Expected merged plugins [a b c], got %v", config.Plugins)
+	}
+	if !reflect.DeepEqual(config.Hosts, []string{"z"}) {
+		t.Errorf("Expected hosts to be replaced, got %v", config.Hosts)
+	}
+
+	// MergeSlices applies to every list
+	config = &Config{}
+	err = New().
+		AddYAML("hosts: [x, y]").
+		AddYAML("hosts: [z]").
+		MergeSlices().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Hosts, []string{"x", "y", "z"}) {
+		t.Errorf("Expected merged hosts [x y z], got %v", config.Hosts)
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+		Addr string
+	}
+
+	var calls []string
+	config := &Config{}
+	err := New().
+		AddMap(map[string]interface{}{"host": "localhost", "port": 8080}).
+		PreLoad(func() error {
+			calls = append(calls, "pre")
+			return nil
+		}).
+		PostLoad(func(c interface{}) error {
+			calls = append(calls, "post")
+			cfg := c.(*Config)
+			cfg.Addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+			return nil
+		}).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Addr != "localhost:8080" {
+		t.Errorf("Expected derived addr localhost:8080, got %s", config.Addr)
+	}
+	if strings.Join(calls, ",") != "pre,post" {
+		t.Errorf("Expected hooks to run in order, got %v", calls)
+	}
+
+	// A failing PreLoad stops the load before any source is read
+	source := &countingSource{}
+	err = New().
+		AddSource(source).
+		PreLoad(func() error { return errors.New("not ready") }).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Errorf("Expected PreLoad error, got: %v", err)
+	}
+	if source.calls != 0 {
+		t.Error("Expected sources not to be read after PreLoad failed")
+	}
+
+	err = New().
+		PostLoad(func(interface{}) error { return errors.New("bad derived value") }).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "bad derived value") {
+		t.Errorf("Expected PostLoad error, got: %v", err)
+	}
+}
+
+func TestSliceOfStructs(t *testing.T) {
+	type Backend struct {
+		URL     string        `cfg:"url" validate:"required,url"`
+		Timeout time.Duration `cfg:"timeout" default:"1s"`
+	}
+	type Config struct {
+		Backends []Backend `cfg:"backends"`
+	}
+
+	config := &Config{}
+	err := New().AddJSON(`{"backends": [
+		{"url": "http://a.local", "timeout": "5s"},
+		{"url": "http://b.local"}
+	]}`).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Backends) != 2 {
+		t.Fatalf("Expected 2 backends, got %d", len(config.Backends))
+	}
+	if config.Backends[0].Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %v", config.Backends[0].Timeout)
+	}
+	if config.Backends[1].Timeout != time.Second {
+		t.Errorf("Expected default timeout 1s, got %v", config.Backends[1].Timeout)
+	}
+
+	err = New().AddJSON(`{"backends": [{"timeout": "5s"}]}`).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "element 0") {
+		t.Errorf("Expected validation error for element 0, got: %v", err)
+	}
+}
+
+func TestNoDefaults(t *testing.T) {
+	type Config struct {
+		Port int    `cfg:"port" default:"8080"`
+		Host string `cfg:"host" default:"localhost"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddMap(map[string]interface{}{"host": "example.com"}).
+		NoDefaults().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 0 {
+		t.Errorf("Expected port to stay at zero, got %d", config.Port)
+	}
+	if config.Host != "example.com" {
+		t.Errorf("Expected provided host to load, got %s", config.Host)
+	}
+}
+
+func TestAddResolvedMap(t *testing.T) {
+	type Database struct {
+		URL    string            `cfg:"url"`
+		Labels map[string]string `cfg:"labels"`
+	}
+	type Config struct {
+		Database Database `cfg:"database"`
+	}
+
+	config := &Config{}
+	err := New().AddResolvedMap(map[string]interface{}{
+		"database.url":    "postgres://localhost/app",
+		"database.labels": map[string]interface{}{"team": "core"},
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Database.URL != "postgres://localhost/app" {
+		t.Errorf("Expected database URL to load, got %s", config.Database.URL)
+	}
+	if config.Database.Labels["team"] != "core" {
+		t.Errorf("Expected labels to load, got %v", config.Database.Labels)
+	}
+}
+
+func TestWithinValidator(t *testing.T) {
+	type Config struct {
+		DataDir string `cfg:"data_dir" validate:"within:/srv/app"`
+	}
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/srv/app/data", false},
+		{"/srv/app", false},
+		{"data/cache", false},
+		{"/srv/app/../etc", true},
+		{"/srv/application", true},
+		{"../../etc/passwd", true},
+	}
+
+	for _, test := range tests {
+		config := &Config{}
+		err := New().AddMap(map[string]interface{}{"data_dir": test.path}).Load(config)
+		if test.wantErr && (err == nil || !strings.Contains(err.Error(), "path must be within /srv/app")) {
+			t.Errorf("Expected %s to be rejected, got: %v", test.path, err)
+		}
+		if !test.wantErr && err != nil {
+			t.Errorf("Expected %s to be accepted, got: %v", test.path, err)
+		}
+	}
+}
+
+func TestContinueOnSourceError(t *testing.T) {
+	type Config struct {
+		Port int    `cfg:"port"`
+		Host string `cfg:"host"`
+	}
+
+	failing := &countingSource{err: errors.New("endpoint down")}
+
+	config := &Config{}
+	loader := New().
+		AddMap(map[string]interface{}{"host": "localhost"}).
+		AddSource(failing).
+		ContinueOnSourceError()
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Expected failing source to be skipped, got: %v", err)
+	}
+	if config.Host != "localhost" {
+		t.Errorf("Expected host from the working source, got %s", config.Host)
+	}
+	if errs := loader.SourceErrors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "endpoint down") {
+		t.Errorf("Expected the skipped error to be recorded, got %v", errs)
+	}
+
+	// Without the option the failure aborts the load
+	err := New().
+		AddMap(map[string]interface{}{"host": "localhost"}).
+		AddSource(failing).
+		Load(&Config{})
+	if err == nil {
+		t.Error("Expected source error without ContinueOnSourceError")
+	}
+
+	// A required source still aborts the load
+	err = New().
+		AddMap(map[string]interface{}{"host": "localhost"}).
+		AddSource(Required(failing)).
+		ContinueOnSourceError().
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "endpoint down") {
+		t.Errorf("Expected required source error, got: %v", err)
+	}
+
+	// So does every source failing
+	err = New().
+		AddSource(failing).
+		ContinueOnSourceError().
+		Load(&Config{})
+	if err == nil {
+		t.Error("Expected error when every source failed")
+	}
+}
+
+func TestInFileValidator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.txt")
+	if err := os.WriteFile(path, []byte("# allowed regions\nus-east-1\neu-west-1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write allow-list: %v", err)
+	}
+
+	// Tags are constant, so exercise the validator with the temp path
+	validator := getBuiltinValidators()["in_file"]
+
+	if err := validator("eu-west-1", path); err != nil {
+		t.Errorf("Expected eu-west-1 to be allowed, got: %v", err)
+	}
+	if err := validator("ap-south-1", path); err == nil {
+		t.Error("Expected ap-south-1 to be rejected")
+	}
+
+	// Edits are picked up once the modification time changes
+	if err := os.WriteFile(path, []byte("ap-south-1\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite allow-list: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to touch allow-list: %v", err)
+	}
+	if err := validator("ap-south-1", path); err != nil {
+		t.Errorf("Expected edited allow-list to be reloaded, got: %v", err)
+	}
+}
+
+func TestNumericComparisonValidators(t *testing.T) {
+	type Config struct {
+		Workers int     `cfg:"workers" validate:"gt:0"`
+		Ratio   float64 `cfg:"ratio" validate:"gte:0.5"`
+		Retries int     `cfg:"retries" validate:"lt:10"`
+		Load    float64 `cfg:"load" validate:"lte:0.9"`
+	}
+
+	valid := map[string]interface{}{"workers": 1, "ratio": 0.5, "retries": 9, "load": 0.9}
+
+	tests := []struct {
+		key     string
+		value   interface{}
+		wantErr string
+	}{
+		{"workers", 0, "value must be greater than 0"},
+		{"ratio", 0.25, "value must be greater than or equal to 0.5"},
+		{"retries", 10, "value must be less than 10"},
+		{"load", 0.95, "value must be less than or equal to 0.9"},
+	}
+
+	if err := New().AddMap(valid).Load(&Config{}); err != nil {
+		t.Fatalf("Expected boundary values to pass, got: %v", err)
+	}
+
+	for _, test := range tests {
+		data := make(map[string]interface{})
+		for k, v := range valid {
+			data[k] = v
+		}
+		data[test.key] = test.value
+
+		err := New().AddMap(data).Load(&Config{})
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s=%v: expected error %q, got: %v", test.key, test.value, test.wantErr, err)
+		}
+	}
+}
+
+func TestDurationValidator(t *testing.T) {
+	type Config struct {
+		Timeout time.Duration `cfg:"timeout" validate:"duration:1s,1h"`
+		Window  string        `cfg:"window" validate:"duration:1s,1h"`
+	}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{"timeout": "30m", "window": "30m"}).Load(config)
+	if err != nil {
+		t.Fatalf("Expected 30m to be within bounds, got: %v", err)
+	}
+	if config.Timeout != 30*time.Minute || config.Window != "30m" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	for _, key := range []string{"timeout", "window"} {
+		err := New().AddMap(map[string]interface{}{key: "2h"}).Load(&Config{})
+		if err == nil || !strings.Contains(err.Error(), "duration must be between 1s and 1h0m0s") {
+			t.Errorf("Expected %s=2h to be rejected, got: %v", key, err)
+		}
+	}
+}
+
+func TestJSONTagsFallback(t *testing.T) {
+	type Config struct {
+		Port    int    `json:"port"`
+		Host    string `json:"host,omitempty" cfg:"hostname"`
+		Ignored string `json:"-"`
+	}
+
+	data := map[string]interface{}{"port": 8080, "host": "json-host", "hostname": "cfg-host"}
+
+	config := &Config{}
+	if err := New().AddMap(data).UseJSONTagsFallback().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected port from json tag, got %d", config.Port)
+	}
+	if config.Host != "cfg-host" {
+		t.Errorf("Expected cfg tag to win over json tag, got %s", config.Host)
+	}
+
+	// Without the option json tags are ignored
+	config = &Config{}
+	if err := New().AddMap(data).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 0 {
+		t.Errorf("Expected json tag to be ignored by default, got %d", config.Port)
+	}
+}
+
+func TestRangeRespectsFieldWidth(t *testing.T) {
+	type Config struct {
+		Quota int64   `cfg:"quota" validate:"range:0,10000000000"`
+		Limit uint64  `cfg:"limit" validate:"range:1,18446744073709551615"`
+		Ratio float64 `cfg:"ratio" validate:"range:0.1,0.9"`
+	}
+
+	config := &Config{}
+	err := New().AddMap(map[string]interface{}{
+		"quota": int64(5000000000),
+		"limit": uint64(18446744073709551000),
+		"ratio": 0.25,
+	}).Load(config)
+	if err != nil {
+		t.Fatalf("Expected values within range, got: %v", err)
+	}
+	if config.Quota != 5000000000 || config.Limit != 18446744073709551000 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	err = New().AddMap(map[string]interface{}{"quota": int64(20000000000)}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "value must be between 0 and 10000000000") {
+		t.Errorf("Expected quota above range to fail, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{"ratio": 0.95}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "value must be between 0.1 and 0.9") {
+		t.Errorf("Expected ratio above range to fail, got: %v", err)
+	}
+}
+
+func TestNestedEnvOverride(t *testing.T) {
+	type Pool struct {
+		Size int `cfg:"size"`
+	}
+	type Database struct {
+		URL  string `cfg:"url"`
+		Pool Pool   `cfg:"pool"`
+	}
+	type Config struct {
+		Database Database `cfg:"database"`
+	}
+
+	t.Setenv("DATABASE_URL", "postgres://env/db")
+	t.Setenv("DATABASE_POOL_SIZE", "20")
+
+	config := &Config{}
+	err := New().
+		AddYAML("database:\n  url: postgres://file/db\n  pool:\n    size: 5").
+		AddEnv().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Database.URL != "postgres://env/db" {
+		t.Errorf("Expected env override for database.url, got %s", config.Database.URL)
+	}
+	if config.Database.Pool.Size != 20 {
+		t.Errorf("Expected env override for database.pool.size, got %d", config.Database.Pool.Size)
+	}
+
+	// Without an env source the file values stay
+	config = &Config{}
+	if err := New().AddYAML("database:\n  url: postgres://file/db").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Database.URL != "postgres://file/db" {
+		t.Errorf("Expected file value without AddEnv, got %s", config.Database.URL)
+	}
+}
+
+func TestOverridesBeatDerivedEnvKey(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `cfg:"database.url"`
+	}
+
+	os.Setenv("DATABASE_URL", "from-env")
+	os.Setenv("TEST_OTHER_URL", "from-binding")
+	defer os.Unsetenv("DATABASE_URL")
+	defer os.Unsetenv("TEST_OTHER_URL")
+
+	config := &Config{}
+	if err := New().AddEnv().AddSetArgs([]string{"database.url=from-set"}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DatabaseURL != "from-set" {
+		t.Errorf("Expected set arg to beat DATABASE_URL, got %s", config.DatabaseURL)
+	}
+
+	config = &Config{}
+	if err := New().AddEnv().BindEnv("database.url", "TEST_OTHER_URL").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DatabaseURL != "from-binding" {
+		t.Errorf("Expected env binding to beat DATABASE_URL, got %s", config.DatabaseURL)
+	}
+}
+
+func TestAddSetArgs(t *testing.T) {
+	type Config struct {
+		Port     int    `cfg:"port"`
+		Database string `cfg:"database.url"`
+	}
+
+	t.Setenv("PORT", "7070")
+
+	config := &Config{}
+	err := New().
+		AddSetArgs([]string{"database.url=postgres://cli/db", "port=9090"}).
+		AddYAML("port: 8080\ndatabase:\n  url: postgres://file/db").
+		AddEnv().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected port from set args, got %d", config.Port)
+	}
+	if config.Database != "postgres://cli/db" {
+		t.Errorf("Expected database URL from set args, got %s", config.Database)
+	}
+
+	err = New().AddSetArgs([]string{"port"}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "expected key=value") {
+		t.Errorf("Expected malformed set argument error, got: %v", err)
+	}
+}
+
+func TestStrictValidators(t *testing.T) {
+	type Config struct {
+		Name string `cfg:"name" validate:"requird"`
+		Port int    `cfg:"port" validate:"range:1,10"`
+	}
+
+	data := map[string]interface{}{"name": "app", "port": 5}
+
+	if err := New().AddMap(data).Load(&Config{}); err != nil {
+		t.Fatalf("Expected unknown rule to be skipped by default, got: %v", err)
+	}
+
+	err := New().AddMap(data).StrictValidators().Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "unknown validation rules for field Name: requird") {
+		t.Errorf("Expected unknown rule error, got: %v", err)
+	}
+
+	// A misspelled rule after a parameterized one is not taken as a parameter
+	type Ranged struct {
+		Port int `cfg:"port" validate:"range:1,10,requird"`
+	}
+	err = New().AddMap(data).StrictValidators().Load(&Ranged{})
+	if err == nil || !strings.Contains(err.Error(), "unknown validation rules for field Port: requird") {
+		t.Errorf("Expected unknown rule error after range, got: %v", err)
+	}
+}
+
+func TestAddFileLayers(t *testing.T) {
+	dir := t.TempDir()
+	layers := map[string]string{
+		"base.yaml":     "port: 8080\nhost: base.local\nmode: dev\n",
+		"region.yaml":   "host: region.local\nmode: staging\n",
+		"override.json": `{"mode": "prod"}`,
+	}
+	for name, content := range layers {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	type Config struct {
+		Port int    `cfg:"port"`
+		Host string `cfg:"host"`
+		Mode string `cfg:"mode"`
+	}
+
+	config := &Config{}
+	err := New().AddFileLayers(
+		filepath.Join(dir, "base.yaml"),
+		filepath.Join(dir, "region.yaml"),
+		filepath.Join(dir, "override.json"),
+	).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 8080 || config.Host != "region.local" || config.Mode != "prod" {
+		t.Errorf("Expected later layers to win, got %+v", config)
+	}
+}
+
+func TestEqFieldValidator(t *testing.T) {
+	type Config struct {
+		Password        string `cfg:"password" validate:"eqfield:PasswordConfirm"`
+		PasswordConfirm string `cfg:"password_confirm"`
+	}
+
+	err := New().AddMap(map[string]interface{}{
+		"password":         "s3cret",
+		"password_confirm": "s3cret",
+	}).Load(&Config{})
+	if err != nil {
+		t.Errorf("Expected matching passwords to pass, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{
+		"password":         "s3cret",
+		"password_confirm": "secret",
+	}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "Password does not match PasswordConfirm") {
+		t.Errorf("Expected mismatch error naming both fields, got: %v", err)
+	}
+}
+
+func TestChangedFromDefaults(t *testing.T) {
+	type Database struct {
+		Pool int `cfg:"pool" default:"10"`
+	}
+	type Config struct {
+		Port     int      `cfg:"port" default:"8080"`
+		Host     string   `cfg:"host" default:"localhost"`
+		Debug    bool     `cfg:"debug"`
+		Token    string   `cfg:"token"`
+		Database Database `cfg:"database"`
+	}
+
+	config := &Config{}
+	loader := New().AddMap(map[string]interface{}{
+		"port":          9090,
+		"debug":         true,
+		"database.pool": 10,
+	})
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	changed, err := loader.ChangedFromDefaults(config)
+	if err != nil {
+		t.Fatalf("ChangedFromDefaults failed: %v", err)
+	}
+	if !reflect.DeepEqual(changed, []string{"Port", "Debug"}) {
+		t.Errorf("Expected [Port Debug], got %v", changed)
+	}
+}
+
+func TestMapOfStructs(t *testing.T) {
+	type Limit struct {
+		RPS   int `cfg:"rps"`
+		Burst int `cfg:"burst" default:"5"`
+	}
+	type Config struct {
+		Limits map[string]Limit `cfg:"limits"`
+	}
+
+	config := &Config{}
+	err := New().AddYAML(`
+limits:
+  default:
+    rps: 10
+  premium:
+    rps: 100
+    burst: 50
+`).Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := map[string]Limit{
+		"default": {RPS: 10, Burst: 5},
+		"premium": {RPS: 100, Burst: 50},
+	}
+	if !reflect.DeepEqual(config.Limits, expected) {
+		t.Errorf("Expected %v, got %v", expected, config.Limits)
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	loader := New().AddYAML("port: 80\nhost: localhost")
+	if _, err := loader.LoadMap(); err != nil {
+		t.Fatalf("LoadMap failed: %v", err)
+	}
+
+	if err := loader.ValidateKey("host", "required,minlen:3"); err != nil {
+		t.Errorf("Expected host to be valid, got: %v", err)
+	}
+
+	err := loader.ValidateKey("port", "range:1000,9999")
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Expected ValidationError, got: %v", err)
+	}
+	if ve.Field != "port" || !strings.Contains(ve.Message, "between 1000 and 9999") {
+		t.Errorf("Unexpected validation error: %+v", ve)
+	}
+
+	if err := loader.ValidateKey("database.url", "required"); err == nil {
+		t.Error("Expected missing key to fail required")
+	}
+}
+
+func TestReloadSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("host: file-v1"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("RS_TOKEN", "env-v1")
+
+	type Config struct {
+		Host  string `cfg:"host"`
+		Token string `env:"RS_TOKEN"`
+	}
+
+	config := &Config{}
+	loader := New().AddFile(path).AddEnv()
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("host: file-v2"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite config: %v", err)
+	}
+	t.Setenv("RS_TOKEN", "env-v2")
+
+	onlyFiles := func(s Source) bool {
+		_, ok := s.(*FileSource)
+		return ok
+	}
+	changed, err := loader.ReloadSources(config, onlyFiles)
+	if err != nil {
+		t.Fatalf("ReloadSources failed: %v", err)
+	}
+
+	if config.Host != "file-v2" {
+		t.Errorf("Expected file change to apply, got %s", config.Host)
+	}
+	if config.Token != "env-v1" {
+		t.Errorf("Expected env to keep its last loaded value, got %s", config.Token)
+	}
+	if !reflect.DeepEqual(changed, []string{"Host"}) {
+		t.Errorf("Expected only Host to change, got %v", changed)
+	}
+}
+
+func TestFloatStringFormat(t *testing.T) {
+	type Config struct {
+		Budget string `cfg:"budget"`
+	}
+	data := map[string]interface{}{"budget": 1e6}
+
+	config := &Config{}
+	if err := New().AddMap(data).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Budget != "1e+06" {
+		t.Errorf("Expected default %%v formatting 1e+06, got %s", config.Budget)
+	}
+
+	config = &Config{}
+	if err := New().AddMap(data).FloatStringFormat('f', -1).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Budget != "1000000" {
+		t.Errorf("Expected 1000000, got %s", config.Budget)
+	}
+}
+
+func TestJSONValidator(t *testing.T) {
+	type Config struct {
+		Policy string `cfg:"policy" validate:"json"`
+		Rules  string `cfg:"rules" validate:"json:object"`
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "valid JSON",
+			data: map[string]interface{}{"policy": `[1, 2]`, "rules": `{"allow": true}`},
+		},
+		{
+			name:    "invalid JSON",
+			data:    map[string]interface{}{"policy": `{"allow": }`},
+			wantErr: "value must be valid JSON",
+		},
+		{
+			name:    "array where object required",
+			data:    map[string]interface{}{"rules": `["allow"]`},
+			wantErr: "value must be a JSON object",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := New().AddMap(test.data).Load(&Config{})
+			if test.wantErr == "" && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("Expected error %q, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAddXDGConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "myapp"), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "myapp", "config.yaml"), []byte("port: 9090"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	type Config struct {
+		Port int `cfg:"port" default:"8080"`
+	}
+
+	config := &Config{}
+	if err := New().AddXDGConfig("myapp").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected port from XDG config, got %d", config.Port)
+	}
+
+	// A missing app directory is not an error
+	config = &Config{}
+	if err := New().AddXDGConfig("otherapp").Load(config); err != nil {
+		t.Fatalf("Expected missing XDG config to be skipped, got: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected default port, got %d", config.Port)
+	}
+}
+
+func TestRegisterFieldParser(t *testing.T) {
+	type Config struct {
+		Ports []int `cfg:"ports"`
+	}
+
+	csvInts := func(raw interface{}) (interface{}, error) {
+		var ports []int
+		for _, part := range strings.Split(fmt.Sprintf("%v", raw), ";") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			ports = append(ports, n)
+		}
+		return ports, nil
+	}
+
+	config := &Config{}
+	err := New().
+		AddMap(map[string]interface{}{"ports": "80; 443; 8080"}).
+		RegisterFieldParser("ports", csvInts).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{80, 443, 8080}) {
+		t.Errorf("Expected [80 443 8080], got %v", config.Ports)
+	}
+
+	err = New().
+		AddMap(map[string]interface{}{"ports": "80; http"}).
+		RegisterFieldParser("ports", csvInts).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "failed to parse field Ports") {
+		t.Errorf("Expected parser error, got: %v", err)
+	}
+}
+
+func TestEmptyEnvAsTrue(t *testing.T) {
+	type Config struct {
+		Debug bool   `cfg:"debug" env:"DEBUG"`
+		Name  string `env:"EMPTY_NAME" default:"app"`
+	}
+
+	t.Setenv("DEBUG", "")
+	t.Setenv("EMPTY_NAME", "")
+
+	config := &Config{}
+	if err := New().AddEnv().EmptyEnvAsTrue().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Debug {
+		t.Error("Expected empty DEBUG to enable the flag")
+	}
+	if config.Name != "" {
+		t.Errorf("Expected only bool fields to be affected, got %q", config.Name)
+	}
+
+	// Without the option an empty value does not parse as a bool
+	if err := New().AddEnv().Load(&Config{}); err == nil {
+		t.Error("Expected empty DEBUG to fail without EmptyEnvAsTrue")
+	}
+}
+
+func TestSameLenValidator(t *testing.T) {
+	type Config struct {
+		Names   []string  `cfg:"names" validate:"samelen:weights"`
+		Weights []float64 `cfg:"weights"`
+	}
+
+	err := New().AddMap(map[string]interface{}{
+		"names":   []interface{}{"a", "b"},
+		"weights": []interface{}{0.5, 0.5},
+	}).Load(&Config{})
+	if err != nil {
+		t.Errorf("Expected equal lengths to pass, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{
+		"names":   []interface{}{"a", "b", "c"},
+		"weights": []interface{}{0.5, 0.5},
+	}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "length 3 does not match weights length 2") {
+		t.Errorf("Expected length mismatch error, got: %v", err)
+	}
+}
+
+func TestAddHostOverride(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":         "port: 8080\nhost: base.local",
+		"config.web-01.yaml":  "host: web-01.local",
+		"config.other-9.yaml": "host: other.local",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	original := hostname
+	defer func() { hostname = original }()
+
+	type Config struct {
+		Port int    `cfg:"port"`
+		Host string `cfg:"host"`
+	}
+
+	hostname = func() (string, error) { return "web-01", nil }
+	config := &Config{}
+	if err := New().AddHostOverride(dir, "config.yaml").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 8080 || config.Host != "web-01.local" {
+		t.Errorf("Expected host overlay to apply, got %+v", config)
+	}
+
+	// A host without an overlay file just gets the base
+	hostname = func() (string, error) { return "db-07", nil }
+	config = &Config{}
+	if err := New().AddHostOverride(dir, "config.yaml").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Host != "base.local" {
+		t.Errorf("Expected base host, got %s", config.Host)
+	}
+}
+
+func TestBoolIntConversion(t *testing.T) {
+	type Config struct {
+		Enabled bool `cfg:"enabled"`
+		Verbose int  `cfg:"verbose"`
+	}
+
+	tests := []struct {
+		data     map[string]interface{}
+		expected Config
+	}{
+		{map[string]interface{}{"enabled": 1, "verbose": true}, Config{Enabled: true, Verbose: 1}},
+		{map[string]interface{}{"enabled": 0, "verbose": false}, Config{Enabled: false, Verbose: 0}},
+		{map[string]interface{}{"enabled": float64(1)}, Config{Enabled: true}},
+	}
+
+	for _, test := range tests {
+		config := &Config{Enabled: !test.expected.Enabled}
+		if err := New().AddMap(test.data).Load(config); err != nil {
+			t.Errorf("%v: failed to load: %v", test.data, err)
+			continue
+		}
+		if *config != test.expected {
+			t.Errorf("%v: expected %+v, got %+v", test.data, test.expected, *config)
+		}
+	}
+
+	err := New().AddMap(map[string]interface{}{"enabled": 2}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "only 0 and 1 are accepted") {
+		t.Errorf("Expected error converting 2 to bool, got: %v", err)
+	}
+}
+
+func TestMultipleOfValidator(t *testing.T) {
+	type Config struct {
+		BufferSize int `cfg:"buffer_size" validate:"multipleof:4096"`
+	}
+
+	config := &Config{}
+	if err := New().AddMap(map[string]interface{}{"buffer_size": 8192}).Load(config); err != nil {
+		t.Fatalf("Expected aligned value to pass, got: %v", err)
+	}
+	if config.BufferSize != 8192 {
+		t.Errorf("Expected 8192, got %d", config.BufferSize)
+	}
+
+	err := New().AddMap(map[string]interface{}{"buffer_size": 5000}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "multiple of 4096") {
+		t.Errorf("Expected misaligned value to fail, got: %v", err)
+	}
+}
+
+func TestWildcardMapKeys(t *testing.T) {
+	type Config struct {
+		Flags map[string]bool `cfg:"feature_flag_*"`
+		Name  string          `cfg:"name"`
+	}
+
+	os.Setenv("FEATURE_FLAG_SEARCH", "true")
+	os.Setenv("FEATURE_FLAG_BETA", "false")
+	os.Setenv("FEATURE_FLAG_DARK_MODE", "true")
+	defer os.Unsetenv("FEATURE_FLAG_SEARCH")
+	defer os.Unsetenv("FEATURE_FLAG_BETA")
+	defer os.Unsetenv("FEATURE_FLAG_DARK_MODE")
+
+	config := &Config{}
+	if err := New().AddEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := map[string]bool{"search": true, "beta": false, "dark_mode": true}
+	if !reflect.DeepEqual(config.Flags, expected) {
+		t.Errorf("Expected %v, got %v", expected, config.Flags)
+	}
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `cfg:"database.url"`
+		MaxConns    int    `cfg:"max_conns"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "database:\n  url: postgres://file/db\nmax_conns: 5\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("DATABASE_URL", "postgres://env/db")
+	defer os.Unsetenv("DATABASE_URL")
+
+	// The env source comes last, so it wins
+	config := &Config{}
+	if err := New().AddFile(path).AddEnv().NormalizeKeys().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DatabaseURL != "postgres://env/db" {
+		t.Errorf("Expected env to override file, got %s", config.DatabaseURL)
+	}
+	if config.MaxConns != 5 {
+		t.Errorf("Expected max_conns 5, got %d", config.MaxConns)
+	}
+
+	// Both sources write the canonical key database.url, so the file now wins
+	config = &Config{}
+	if err := New().AddEnv().AddFile(path).NormalizeKeys().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DatabaseURL != "postgres://file/db" {
+		t.Errorf("Expected file to override env, got %s", config.DatabaseURL)
+	}
+}
+
+func TestSequenceOfTablesFromFile(t *testing.T) {
+	type TLS struct {
+		Cert string `cfg:"cert"`
+	}
+	type ServerConfig struct {
+		Name string `cfg:"name" validate:"required"`
+		Port int    `cfg:"port" default:"80"`
+		TLS  TLS    `cfg:"tls"`
+	}
+	type Config struct {
+		Servers []ServerConfig `cfg:"servers"`
+	}
+
+	// The YAML equivalent of two TOML [[servers]] tables
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `servers:
+  - name: alpha
+    port: 8080
+    tls:
+      cert: /etc/alpha.pem
+  - name: beta
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{}
+	if err := New().AddFile(path).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := []ServerConfig{
+		{Name: "alpha", Port: 8080, TLS: TLS{Cert: "/etc/alpha.pem"}},
+		{Name: "beta", Port: 80},
+	}
+	if !reflect.DeepEqual(config.Servers, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config.Servers)
+	}
+}
+
+func TestTimeFormatLayouts(t *testing.T) {
+	type Config struct {
+		Start time.Time `cfg:"start" timeformat:"2006-01-02T15:04:05Z07:00|02/01/2006"`
+		End   time.Time `cfg:"end"`
+	}
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-03-15T10:30:00Z", time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"15/03/2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		config := &Config{}
+		err := New().
+			AddMap(map[string]interface{}{"start": test.input, "end": "2024-12-31T00:00:00Z"}).
+			Load(config)
+		if err != nil {
+			t.Errorf("%s: failed to load: %v", test.input, err)
+			continue
+		}
+		if !config.Start.Equal(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.input, test.expected, config.Start)
+		}
+	}
+
+	err := New().AddMap(map[string]interface{}{"start": "March 15"}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "02/01/2006") {
+		t.Errorf("Expected error naming the accepted layouts, got: %v", err)
+	}
+}
+
+func TestRemainingKeys(t *testing.T) {
+	type Plugin struct {
+		Name  string                 `cfg:"name"`
+		Extra map[string]interface{} `cfg:",remaining"`
+	}
+	type Config struct {
+		Port     int                    `cfg:"port"`
+		Plugin   Plugin                 `cfg:"plugin"`
+		CatchAll map[string]interface{} `cfg:",remaining"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddYAML("port: 8080\ncolor: blue\nretries: 3\nplugin:\n  name: audit\n  level: debug\n").
+		Strict().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Expected catch-all to absorb unknown keys, got: %v", err)
+	}
+
+	if config.Port != 8080 || config.Plugin.Name != "audit" {
+		t.Errorf("Expected declared fields to load, got %+v", config)
+	}
+	expected := map[string]interface{}{"color": "blue", "retries": 3}
+	if !reflect.DeepEqual(config.CatchAll, expected) {
+		t.Errorf("Expected catch-all %v, got %v", expected, config.CatchAll)
+	}
+	if !reflect.DeepEqual(config.Plugin.Extra, map[string]interface{}{"level": "debug"}) {
+		t.Errorf("Expected plugin extras {level: debug}, got %v", config.Plugin.Extra)
+	}
+}
+
+func TestRequiredEnvVar(t *testing.T) {
+	type Config struct {
+		APIKey string `cfg:"api_key" env:"TEST_API_KEY,required"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("api_key: from-file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Unsetenv("TEST_API_KEY")
+	err := New().AddFile(path).AddEnv().Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "requires environment variable TEST_API_KEY") {
+		t.Fatalf("Expected missing env var to fail even with a file value, got: %v", err)
+	}
+
+	os.Setenv("TEST_API_KEY", "from-env")
+	defer os.Unsetenv("TEST_API_KEY")
+
+	config := &Config{}
+	if err := New().AddFile(path).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.APIKey != "from-env" {
+		t.Errorf("Expected value from env, got %s", config.APIKey)
+	}
+}
+
+func TestFilesOverrideEnv(t *testing.T) {
+	type Config struct {
+		Port        int    `cfg:"port"`
+		DatabaseURL string `cfg:"database.url"`
+		Debug       bool   `cfg:"debug"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "port: 8080\ndatabase:\n  url: postgres://sealed/db\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("PORT", "9090")
+	os.Setenv("DATABASE_URL", "postgres://env/db")
+	os.Setenv("DEBUG", "true")
+	defer os.Unsetenv("PORT")
+	defer os.Unsetenv("DATABASE_URL")
+	defer os.Unsetenv("DEBUG")
+
+	config := &Config{}
+	if err := New().AddFile(path).AddEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected env to win by default, got %d", config.Port)
+	}
+
+	config = &Config{}
+	if err := New().AddFile(path).AddEnv().FilesOverrideEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected file port to win, got %d", config.Port)
+	}
+	if config.DatabaseURL != "postgres://sealed/db" {
+		t.Errorf("Expected file database.url to win over DATABASE_URL, got %s", config.DatabaseURL)
+	}
+	if !config.Debug {
+		t.Error("Expected env to still provide keys the file lacks")
+	}
+
+	// Remote sources are not files, whatever their Priority
+	remote := func(ctx context.Context) ([]byte, string, error) {
+		return []byte("port: 7070\n"), "yaml", nil
+	}
+	config = &Config{}
+	if err := New().AddEnv().AddRemoteFunc(remote).FilesOverrideEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 7070 {
+		t.Errorf("Expected remote port added after env to win, got %d", config.Port)
+	}
+	config = &Config{}
+	if err := New().AddRemoteFunc(remote).AddEnv().FilesOverrideEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected env added after remote to win, got %d", config.Port)
+	}
+
+	// Wrapped files still count as files
+	config = &Config{}
+	if err := New().AddSource(Required(&FileSource{Path: path})).AddEnv().FilesOverrideEnv().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected required file port to win, got %d", config.Port)
+	}
+}
+
+func TestRequireEnv(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"port"`
+	}
+
+	os.Setenv("TEST_REQUIRED_PRESENT", "1")
+	defer os.Unsetenv("TEST_REQUIRED_PRESENT")
+	os.Unsetenv("TEST_REQUIRED_MISSING")
+
+	source := &countingSource{}
+	err := New().
+		RequireEnv("TEST_REQUIRED_PRESENT", "TEST_REQUIRED_MISSING").
+		AddSource(source).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "TEST_REQUIRED_MISSING") {
+		t.Fatalf("Expected error naming the missing variable, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "TEST_REQUIRED_PRESENT") {
+		t.Errorf("Expected only missing variables in the error, got: %v", err)
+	}
+	if source.calls != 0 {
+		t.Error("Expected sources not to be read")
+	}
+
+	if err := New().RequireEnv("TEST_REQUIRED_PRESENT").Load(&Config{}); err != nil {
+		t.Errorf("Expected present variable to pass, got: %v", err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	type Endpoint struct {
+		URL  string `cfg:"url" validate:"required"`
+		Port int    `cfg:"port" default:"80"`
+	}
+	type Config struct {
+		Name string `cfg:"name"`
+	}
+
+	var config Config
+	var db, cache Endpoint
+	err := New().
+		AddYAML("name: api\ndatabase:\n  url: postgres://db\n  port: 5432\ncache:\n  url: redis://cache\n").
+		Group("database", &db).
+		Group("cache", &cache).
+		Strict().
+		Load(&config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Name != "api" {
+		t.Errorf("Expected name api, got %s", config.Name)
+	}
+	if db != (Endpoint{URL: "postgres://db", Port: 5432}) {
+		t.Errorf("Unexpected database group: %+v", db)
+	}
+	if cache != (Endpoint{URL: "redis://cache", Port: 80}) {
+		t.Errorf("Unexpected cache group: %+v", cache)
+	}
+
+	err = New().AddYAML("database:\n  port: 5432\n").Group("database", &Endpoint{}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "URL") {
+		t.Errorf("Expected group validation error, got: %v", err)
+	}
+}
+
+func TestReloadGroup(t *testing.T) {
+	type Endpoint struct {
+		URL  string `cfg:"url,noreload"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Name string `cfg:"name"`
+	}
+
+	data := map[string]interface{}{"name": "api", "database.url": "u1", "database.port": 5432}
+	var db Endpoint
+	loader := New().AddMap(data).Group("database", &db)
+
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	data["database.url"] = "u2"
+	data["database.port"] = 6432
+
+	changed, err := loader.Reload(config)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	if db.URL != "u1" {
+		t.Errorf("Expected noreload group URL to stay u1, got %s", db.URL)
+	}
+	if db.Port != 6432 {
+		t.Errorf("Expected group port 6432 after reload, got %d", db.Port)
+	}
+
+	expected := []string{"database.URL (noreload)", "database.Port"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected changed %v, got %v", expected, changed)
+	}
+}
+
+func TestFieldOrdering(t *testing.T) {
+	type Config struct {
+		MinTimeout time.Duration `cfg:"min_timeout" validate:"ltefield:MaxTimeout"`
+		MaxTimeout time.Duration `cfg:"max_timeout" validate:"gtefield:min_timeout"`
+		Low        float64       `cfg:"low" validate:"ltefield:High"`
+		High       int           `cfg:"high"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddMap(map[string]interface{}{"min_timeout": "1s", "max_timeout": "30s", "low": 1.5, "high": 2}).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Expected ordered fields to pass, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{"min_timeout": "1m", "max_timeout": "30s"}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "less than or equal to MaxTimeout") {
+		t.Errorf("Expected inverted durations to fail, got: %v", err)
+	}
+
+	err = New().AddMap(map[string]interface{}{"low": 3.5, "high": 2}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "High") {
+		t.Errorf("Expected inverted numbers to fail, got: %v", err)
+	}
+
+	if err := New().AddMap(map[string]interface{}{"min_timeout": "1m"}).Load(&Config{}); err != nil {
+		t.Errorf("Expected unset sibling to be skipped, got: %v", err)
+	}
+}
+
+func TestLoadInto(t *testing.T) {
+	type Pool struct {
+		Size int `cfg:"size"`
+	}
+	type Database struct {
+		URL  string `cfg:"url" validate:"required"`
+		Pool Pool   `cfg:"pool"`
+	}
+	type Config struct {
+		Name     string   `cfg:"name"`
+		Database Database `cfg:"database"`
+	}
+
+	yaml := "name: changed\ndatabase:\n  url: postgres://new\n  pool:\n    size: 20\n"
+
+	config := &Config{Name: "original", Database: Database{URL: "postgres://old"}}
+	if err := New().AddYAML(yaml).LoadInto(config, "database"); err != nil {
+		t.Fatalf("Failed to load section: %v", err)
+	}
+	if config.Name != "original" {
+		t.Errorf("Expected fields outside the section to be untouched, got %s", config.Name)
+	}
+	if config.Database.URL != "postgres://new" || config.Database.Pool.Size != 20 {
+		t.Errorf("Expected database section to load, got %+v", config.Database)
+	}
+
+	config = &Config{}
+	if err := New().AddYAML(yaml).LoadInto(config, "database.pool"); err != nil {
+		t.Fatalf("Failed to load nested section: %v", err)
+	}
+	if config.Database.Pool.Size != 20 || config.Database.URL != "" {
+		t.Errorf("Expected only the pool section to load, got %+v", config.Database)
+	}
+
+	if err := New().AddYAML(yaml).LoadInto(&Config{}, "name"); err == nil {
+		t.Error("Expected error for a path that is not a struct section")
+	}
+}
+
+func TestWarnRules(t *testing.T) {
+	type Config struct {
+		Workers int    `cfg:"workers" validate:"min:1,warn:range:1,100"`
+		Owner   string `cfg:"owner" validate:"warn:required"`
+	}
+
+	loader := New().AddMap(map[string]interface{}{"workers": 500})
+	config := &Config{}
+	if err := loader.Load(config); err != nil {
+		t.Fatalf("Expected warnings not to fail the load, got: %v", err)
+	}
+	if config.Workers != 500 {
+		t.Errorf("Expected workers 500, got %d", config.Workers)
+	}
+
+	warnings := loader.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	if warnings[0].Field != "Workers" || warnings[0].Rule != "range:1,100" {
+		t.Errorf("Unexpected first warning: %+v", warnings[0])
+	}
+	if warnings[1].Field != "Owner" {
+		t.Errorf("Unexpected second warning: %+v", warnings[1])
+	}
+
+	// Non-advisory rules still fail
+	if err := New().AddMap(map[string]interface{}{"workers": 0}).Load(&Config{}); err == nil {
+		t.Error("Expected min rule to fail the load")
+	}
+
+	if err := loader.AddMap(map[string]interface{}{"workers": 50, "owner": "ops"}).Load(config); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if len(loader.Warnings()) != 0 {
+		t.Errorf("Expected warnings to reset on reload, got %v", loader.Warnings())
+	}
+}
+
+func TestMapEnv(t *testing.T) {
+	type Config struct {
+		Port int    `cfg:"port"`
+		Mode string `cfg:"mode" default:"dev"`
+	}
+
+	os.Setenv("LEGACY_PORT", "7070")
+	os.Setenv("MODE", "prod")
+	defer os.Unsetenv("LEGACY_PORT")
+	defer os.Unsetenv("MODE")
+
+	config := &Config{}
+	err := New().
+		AddEnv().
+		MapEnv(func(key, value string) (string, string, bool) {
+			switch key {
+			case "LEGACY_PORT":
+				return "port", value, true
+			case "MODE":
+				return key, value, false
+			}
+			return key, value, true
+		}).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 7070 {
+		t.Errorf("Expected renamed LEGACY_PORT to load as port, got %d", config.Port)
+	}
+	if config.Mode != "dev" {
+		t.Errorf("Expected dropped MODE to leave the default, got %s", config.Mode)
+	}
+}
+
+func TestEnvJSON(t *testing.T) {
+	type Search struct {
+		Enabled bool `cfg:"enabled"`
+		Limit   int  `cfg:"limit"`
+	}
+	type Config struct {
+		Features struct {
+			Beta   bool   `cfg:"beta"`
+			Search Search `cfg:"search"`
+		} `cfg:"features"`
+		Regions []string `cfg:"regions"`
+	}
+
+	os.Setenv("FEATURES", `{"beta": true, "search": {"enabled": true, "limit": 25}}`)
+	os.Setenv("REGIONS", `["eu", "us"]`)
+	defer os.Unsetenv("FEATURES")
+	defer os.Unsetenv("REGIONS")
+
+	config := &Config{}
+	if err := New().AddEnv().EnvJSON("FEATURES", "regions").Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !config.Features.Beta || !config.Features.Search.Enabled || config.Features.Search.Limit != 25 {
+		t.Errorf("Expected nested JSON keys to load, got %+v", config.Features)
+	}
+	if !reflect.DeepEqual(config.Regions, []string{"eu", "us"}) {
+		t.Errorf("Expected regions [eu us], got %v", config.Regions)
+	}
+
+	os.Setenv("FEATURES", `{"beta": `)
+	err := New().AddEnv().EnvJSON("FEATURES").Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "invalid JSON in FEATURES") {
+		t.Errorf("Expected invalid JSON error, got: %v", err)
+	}
+}
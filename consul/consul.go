@@ -0,0 +1,163 @@
+// Package consul provides a configflow source that reads a Consul KV
+// subtree over the HTTP API, with blocking queries to watch for changes.
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+// Source loads the keys below Prefix. Folder paths become dotted keys, so
+// with Prefix "app/" the key "app/database/url" becomes "database.url", and
+// values are parsed with configflow.ParseValue.
+type Source struct {
+	Address string // Consul address, defaults to $CONSUL_HTTP_ADDR or http://127.0.0.1:8500
+	Token   string // ACL token, defaults to $CONSUL_HTTP_TOKEN
+	Prefix  string
+	Client  *http.Client
+
+	mu    sync.Mutex
+	index uint64 // X-Consul-Index of the last read
+}
+
+// New creates a source for the keys below prefix
+func New(address, prefix string) *Source {
+	return &Source{Address: address, Prefix: prefix}
+}
+
+func (s *Source) Priority() int { return 1 } // Same as files
+
+func (s *Source) Load() (map[string]interface{}, error) {
+	return s.LoadContext(context.Background())
+}
+
+func (s *Source) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	result, _, err := s.fetch(ctx, 0)
+	return result, err
+}
+
+// Watch runs blocking queries against the prefix and calls onChange each
+// time its contents change, typically to Reload the config. It blocks
+// until ctx is canceled, returning nil, or a query fails. A response
+// without an X-Consul-Index fails the watch, since queries would no
+// longer block.
+func (s *Source) Watch(ctx context.Context, onChange func()) error {
+	s.mu.Lock()
+	index := s.index
+	s.mu.Unlock()
+
+	// Without a previous load, start from the current state
+	if index == 0 {
+		var err error
+		if _, index, err = s.fetch(ctx, 0); err != nil {
+			return err
+		}
+	}
+
+	for {
+		_, next, err := s.fetch(ctx, index)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if next == 0 {
+			return fmt.Errorf("consul prefix %s: response has no X-Consul-Index", s.Prefix)
+		}
+
+		// The index can go backwards after a Consul restore; start over
+		if next < index {
+			index = 0
+			continue
+		}
+		if next != index {
+			index = next
+			onChange()
+		}
+	}
+}
+
+// entry is a key as returned by the KV API, with a base64-encoded value
+type entry struct {
+	Key   string `json:"Key"`
+	Value []byte `json:"Value"`
+}
+
+// fetch reads the subtree, blocking until the index moves past waitIndex
+// when it is non-zero
+func (s *Source) fetch(ctx context.Context, waitIndex uint64) (map[string]interface{}, uint64, error) {
+	address := s.Address
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	query := url.Values{"recurse": {"true"}}
+	if waitIndex > 0 {
+		query.Set("index", strconv.FormatUint(waitIndex, 10))
+	}
+	endpoint := strings.TrimRight(address, "/") + "/v1/kv/" + strings.TrimLeft(s.Prefix, "/") + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	token := s.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read consul prefix %s: %w", s.Prefix, err)
+	}
+	defer resp.Body.Close()
+
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+
+	result := make(map[string]interface{})
+	if resp.StatusCode == http.StatusNotFound {
+		return result, index, nil // Empty prefix
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to read consul prefix %s: %s", s.Prefix, resp.Status)
+	}
+
+	var entries []entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+	for _, e := range entries {
+		key := strings.Trim(strings.TrimPrefix(e.Key, strings.TrimLeft(s.Prefix, "/")), "/")
+		if key == "" || strings.HasSuffix(e.Key, "/") {
+			continue // Folder entries carry no value
+		}
+		result[strings.ReplaceAll(key, "/", ".")] = configflow.ParseValue(string(e.Value))
+	}
+	return result, index, nil
+}
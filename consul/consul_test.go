@@ -0,0 +1,123 @@
+package consul
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+func TestConsulSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/app/" || r.URL.Query().Get("recurse") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Consul-Index", "5")
+		fmt.Fprintf(w, `[
+			{"Key": "app/", "Value": null},
+			{"Key": "app/port", "Value": %q},
+			{"Key": "app/database/url", "Value": %q}
+		]`, base64.StdEncoding.EncodeToString([]byte("9090")), base64.StdEncoding.EncodeToString([]byte("postgres://consul/db")))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Port     int    `cfg:"port"`
+		Database string `cfg:"database.url"`
+	}
+
+	config := &Config{}
+	if err := configflow.New().AddSource(New(server.URL, "app/")).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Port != 9090 || config.Database != "postgres://consul/db" {
+		t.Errorf("Unexpected config from consul: %+v", config)
+	}
+}
+
+func TestConsulWatch(t *testing.T) {
+	var indexes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		indexes = append(indexes, r.URL.Query().Get("index"))
+		// Every blocking query returns a newer index
+		w.Header().Set("X-Consul-Index", fmt.Sprint(5+len(indexes)))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	source := New(server.URL, "app/")
+	if _, err := source.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := 0
+	err := source.Watch(ctx, func() {
+		changes++
+		if changes == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if changes != 2 {
+		t.Errorf("Expected two changes, got %d", changes)
+	}
+	// The initial load, then blocking queries from the last seen index
+	if len(indexes) < 3 || indexes[0] != "" || indexes[1] != "6" || indexes[2] != "7" {
+		t.Errorf("Unexpected blocking query indexes: %v", indexes)
+	}
+}
+
+func TestConsulWatchWithoutIndex(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := New(server.URL, "app/").Watch(ctx, func() {})
+	if err == nil || !strings.Contains(err.Error(), "X-Consul-Index") {
+		t.Errorf("Expected missing index error, got: %v", err)
+	}
+	if requests > 2 {
+		t.Errorf("Expected watch to stop instead of polling, got %d requests", requests)
+	}
+}
+
+func TestConsulWatchIndexReset(t *testing.T) {
+	var indexes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		indexes = append(indexes, r.URL.Query().Get("index"))
+		// A restore moves the index back from 10 to 3
+		index := map[int]string{1: "10", 2: "3"}[len(indexes)]
+		if index == "" {
+			index = "4"
+		}
+		w.Header().Set("X-Consul-Index", index)
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := New(server.URL, "app/").Watch(ctx, cancel)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	// After the index went back, the query starts over without an index
+	if len(indexes) < 3 || indexes[1] != "10" || indexes[2] != "" {
+		t.Errorf("Unexpected blocking query indexes: %v", indexes)
+	}
+}
@@ -0,0 +1,43 @@
+package configflow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBSource loads configuration from a SQL query returning (key, value)
+// string columns, such as rows of a per-tenant settings table. Keys are
+// dotted keys and values are parsed like environment variables.
+type DBSource struct {
+	DB    *sql.DB
+	Query string
+	Args  []interface{}
+}
+
+func (ds *DBSource) Priority() int { return 1 } // Same as files
+
+func (ds *DBSource) Load() (map[string]interface{}, error) {
+	return ds.LoadContext(context.Background())
+}
+
+func (ds *DBSource) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	rows, err := ds.DB.QueryContext(ctx, ds.Query, ds.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]interface{})
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan config row: %w", err)
+		}
+		result[key] = parseValue(value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config rows: %w", err)
+	}
+	return result, nil
+}
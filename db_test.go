@@ -0,0 +1,75 @@
+package configflow
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+)
+
+// fakeDriver serves a fixed set of (key, value) rows for any query
+type fakeDriver struct {
+	rows [][2]string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{c.d}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (s *fakeStmt) Close() error                               { return nil }
+func (s *fakeStmt) NumInput() int                              { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{rows: s.d.rows}, nil }
+
+type fakeRows struct {
+	rows [][2]string
+	i    int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"key", "value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[r.i][0], r.rows[r.i][1]
+	r.i++
+	return nil
+}
+
+func TestAddDB(t *testing.T) {
+	sql.Register("configflow-fake", &fakeDriver{rows: [][2]string{
+		{"database.url", "postgres://tenant/db"},
+		{"port", "9090"},
+	}})
+	db, err := sql.Open("configflow-fake", "")
+	if err != nil {
+		t.Fatalf("Failed to open fake database: %v", err)
+	}
+	defer db.Close()
+
+	type Config struct {
+		Port     int    `cfg:"port"`
+		Database string `cfg:"database.url"`
+	}
+
+	config := &Config{}
+	err = New().
+		AddMap(map[string]interface{}{"port": 8080}).
+		AddDB(db, "SELECT key, value FROM settings WHERE tenant = $1", "acme").
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 9090 || config.Database != "postgres://tenant/db" {
+		t.Errorf("Unexpected config from rows: %+v", config)
+	}
+}
@@ -0,0 +1,82 @@
+package configflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkStrictDecode rejects documents that decode ambiguously: JSON objects
+// with duplicate keys, which encoding/json silently resolves to the last
+// one, and YAML files with more than one document, of which only the first
+// would be read. yaml.v3 already rejects duplicate mapping keys.
+func checkStrictDecode(data []byte, format string) error {
+	switch format {
+	case "json":
+		return checkJSONDuplicates(data)
+	case "jsonc":
+		return checkJSONDuplicates(stripJSONC(data))
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		var doc, extra interface{}
+		if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+			return fmt.Errorf("expected a single YAML document")
+		}
+	}
+	return nil
+}
+
+func checkJSONDuplicates(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return walkJSON(dec, "")
+}
+
+// walkJSON consumes one JSON value from dec, failing on duplicate keys
+func walkJSON(dec *json.Decoder, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			if seen[key] {
+				return fmt.Errorf("duplicate key %s", joinPath(path, key))
+			}
+			seen[key] = true
+			if err := walkJSON(dec, joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := walkJSON(dec, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Closing delimiter
+	_, err = dec.Token()
+	return err
+}
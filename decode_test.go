@@ -0,0 +1,56 @@
+package configflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictDecode(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"dup.yaml":   "port: 8080\nport: 9090\n",
+		"dup.json":   `{"port": 8080, "db": {"url": "a", "url": "b"}}`,
+		"multi.yaml": "port: 8080\n---\nport: 9090\n",
+		"ok.json":    `{"port": 8080, "db": {"url": "a"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	type Config struct {
+		Port int `cfg:"port"`
+	}
+
+	// yaml.v3 rejects duplicate keys in either mode
+	err := New().AddFile(filepath.Join(dir, "dup.yaml")).StrictDecode().Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), `mapping key "port" already defined`) {
+		t.Errorf("Expected duplicate YAML key error, got: %v", err)
+	}
+
+	tests := []struct {
+		file    string
+		wantErr string
+	}{
+		{"dup.json", "duplicate key db.url"},
+		{"multi.yaml", "expected a single YAML document"},
+		{"ok.json", ""},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.file)
+		if err := New().AddFile(path).Load(&Config{}); err != nil {
+			t.Errorf("%s: expected lenient decode to pass, got: %v", test.file, err)
+		}
+
+		err := New().AddFile(path).StrictDecode().Load(&Config{})
+		if test.wantErr == "" && err != nil {
+			t.Errorf("%s: expected strict decode to pass, got: %v", test.file, err)
+		}
+		if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+			t.Errorf("%s: expected error %q, got: %v", test.file, test.wantErr, err)
+		}
+	}
+}
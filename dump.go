@@ -0,0 +1,178 @@
+package configflow
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dump converts a populated config struct back into a nested map keyed by
+// the cfg tags of its fields. It is the inverse of loading: the result can
+// be passed to AddMap, or marshaled to YAML/JSON and read with AddReader,
+// to reproduce the same struct. Nested structs follow the same prefix
+// rules as loading, nil pointers are omitted, durations are written as
+// duration strings and base64 fields are re-encoded.
+func Dump(config interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(config)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct or pointer to struct")
+	}
+
+	result := make(map[string]interface{})
+	dumpFields(New(), v, "", result)
+	return result, nil
+}
+
+func dumpFields(l *Loader, v reflect.Value, prefix string, result map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		cfg := l.getFieldConfig(fieldType)
+		if cfg.skip {
+			continue
+		}
+		key := cfg.cfgKey
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		}
+
+		field := v.Field(i)
+		if isNestedStruct(field.Type()) {
+			nestedPrefix := prefix
+			if cfg.cfgKey != "" {
+				nestedPrefix = key
+			}
+			dumpFields(l, field, nestedPrefix, result)
+			continue
+		}
+
+		if key == "" {
+			continue
+		}
+
+		if cfg.has("base64") {
+			if b, ok := field.Interface().([]byte); ok {
+				setPath(result, key, base64.StdEncoding.EncodeToString(b))
+			} else {
+				setPath(result, key, base64.StdEncoding.EncodeToString([]byte(field.String())))
+			}
+			continue
+		}
+
+		if value, ok := dumpValue(field); ok {
+			setPath(result, key, value)
+		}
+	}
+}
+
+// dumpValue converts a field value into plain data suitable for YAML/JSON.
+// It reports false for values that should be omitted.
+func dumpValue(v reflect.Value) (interface{}, bool) {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), true
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return dumpValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, false
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true
+		}
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item, ok := dumpValue(v.Index(i)); ok {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, false
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if item, ok := dumpValue(iter.Value()); ok {
+				m[fmt.Sprintf("%v", iter.Key().Interface())] = item
+			}
+		}
+		return m, true
+	case reflect.Struct:
+		if isNestedStruct(v.Type()) {
+			m := make(map[string]interface{})
+			dumpFields(New(), v, "", m)
+			return m, true
+		}
+	case reflect.Chan, reflect.Func:
+		return nil, false
+	}
+
+	return v.Interface(), true
+}
+
+// lookupPath returns the value at the dotted path below root, descending
+// into maps by key and into lists by index
+func lookupPath(root interface{}, path string) (interface{}, error) {
+	current := root
+	traversed := ""
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("key %s not found", joinPath(traversed, part))
+			}
+			current = value
+		default:
+			items, ok := toList(node)
+			if !ok {
+				return nil, fmt.Errorf("cannot traverse %s: %T is not a map or list", traversed, node)
+			}
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(items) {
+				return nil, fmt.Errorf("invalid index %s into %s of length %d", part, traversed, len(items))
+			}
+			current = items[i]
+		}
+		traversed = joinPath(traversed, part)
+	}
+	return current, nil
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// setPath stores value in a nested map at the dotted key
+func setPath(m map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := m[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[part] = child
+		}
+		m = child
+	}
+	m[parts[len(parts)-1]] = value
+}
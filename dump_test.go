@@ -0,0 +1,121 @@
+package configflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type dumpDatabase struct {
+	URL      string        `cfg:"url"`
+	MaxConns int           `cfg:"pool.max_conns"`
+	Timeout  time.Duration `cfg:"timeout"`
+}
+
+type dumpConfig struct {
+	Name     string            `cfg:"name"`
+	Hosts    []string          `cfg:"hosts"`
+	Ports    []int             `cfg:"ports"`
+	Labels   map[string]string `cfg:"labels"`
+	Debug    *bool             `cfg:"debug"`
+	Key      []byte            `cfg:"key,base64"`
+	Database dumpDatabase      `cfg:"database"`
+	Server   struct {
+		Port int `cfg:"server.port"`
+	}
+}
+
+func newDumpConfig() *dumpConfig {
+	debug := false
+	config := &dumpConfig{
+		Name:   "app",
+		Hosts:  []string{"a.example.com", "b.example.com"},
+		Ports:  []int{80, 443},
+		Labels: map[string]string{"team": "core"},
+		Debug:  &debug,
+		Key:    []byte{0x00, 0xff},
+		Database: dumpDatabase{
+			URL:      "postgres://localhost/app",
+			MaxConns: 20,
+			Timeout:  5 * time.Second,
+		},
+	}
+	config.Server.Port = 8080
+	return config
+}
+
+func TestDumpRoundTripMap(t *testing.T) {
+	original := newDumpConfig()
+
+	dumped, err := Dump(original)
+	if err != nil {
+		t.Fatalf("Failed to dump config: %v", err)
+	}
+
+	loaded := &dumpConfig{}
+	if err := New().AddMap(dumped).Load(loaded); err != nil {
+		t.Fatalf("Failed to load dumped config: %v", err)
+	}
+
+	if !reflect.DeepEqual(original, loaded) {
+		t.Errorf("Round trip mismatch:\noriginal: %+v\nloaded:   %+v", original, loaded)
+	}
+}
+
+func TestDumpRoundTripYAML(t *testing.T) {
+	original := newDumpConfig()
+
+	dumped, err := Dump(original)
+	if err != nil {
+		t.Fatalf("Failed to dump config: %v", err)
+	}
+	out, err := yaml.Marshal(dumped)
+	if err != nil {
+		t.Fatalf("Failed to marshal dump: %v", err)
+	}
+
+	loaded := &dumpConfig{}
+	if err := New().AddYAML(string(out)).Load(loaded); err != nil {
+		t.Fatalf("Failed to load dumped YAML: %v", err)
+	}
+
+	if !reflect.DeepEqual(original, loaded) {
+		t.Errorf("Round trip mismatch:\noriginal: %+v\nloaded:   %+v\nyaml:\n%s", original, loaded, out)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	loader := New().AddYAML(`
+database:
+  pool:
+    size: 10
+servers:
+  - host: a.local
+  - host: b.local
+`)
+	if _, err := loader.LoadMap(); err != nil {
+		t.Fatalf("LoadMap failed: %v", err)
+	}
+
+	size, err := loader.GetPath("database.pool.size")
+	if err != nil || size != 10 {
+		t.Errorf("Expected pool size 10, got %v, %v", size, err)
+	}
+
+	host, err := loader.GetPath("servers.1.host")
+	if err != nil || host != "b.local" {
+		t.Errorf("Expected b.local, got %v, %v", host, err)
+	}
+
+	if _, err := loader.GetPath("database.pool.size.max"); err == nil {
+		t.Error("Expected error traversing a scalar")
+	}
+	if _, err := loader.GetPath("servers.5.host"); err == nil {
+		t.Error("Expected error for an out of range index")
+	}
+	if _, err := loader.GetPath("database.missing"); err == nil {
+		t.Error("Expected error for a missing key")
+	}
+}
@@ -0,0 +1,151 @@
+// Package etcd provides a configflow source that reads the keys below a
+// prefix from etcd through its v3 JSON gateway, and can watch them for
+// changes to trigger reloads.
+package etcd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Source loads the keys below Prefix. Keys map to config keys by trimming
+// the prefix and turning slashes into dots, so with Prefix "/app/" the key
+// "/app/database/url" becomes "database.url".
+type Source struct {
+	Endpoint string // etcd client URL, e.g. http://localhost:2379
+	Prefix   string
+	Client   *http.Client
+}
+
+// New creates a source for the keys below prefix
+func New(endpoint, prefix string) *Source {
+	return &Source{Endpoint: endpoint, Prefix: prefix}
+}
+
+func (s *Source) Priority() int { return 1 } // Same as files
+
+func (s *Source) Load() (map[string]interface{}, error) {
+	return s.LoadContext(context.Background())
+}
+
+func (s *Source) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := s.post(ctx, "/v3/kv/range", s.keyRange())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read etcd prefix %s: %w", s.Prefix, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Kvs []keyValue `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+
+	result := make(map[string]interface{}, len(body.Kvs))
+	for _, kv := range body.Kvs {
+		result[s.configKey(string(kv.Key))] = string(kv.Value)
+	}
+	return result, nil
+}
+
+// Watch subscribes to changes below the prefix and calls onChange after
+// each batch of events, typically to Reload the config. It blocks until
+// ctx is canceled, returning nil, or the watch stream ends.
+func (s *Source) Watch(ctx context.Context, onChange func()) error {
+	resp, err := s.post(ctx, "/v3/watch", map[string]interface{}{"create_request": s.keyRange()})
+	if err != nil {
+		return fmt.Errorf("failed to watch etcd prefix %s: %w", s.Prefix, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			Result struct {
+				Events []json.RawMessage `json:"events"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return fmt.Errorf("failed to decode etcd watch event: %w", err)
+		}
+		if len(msg.Result.Events) > 0 {
+			onChange()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("etcd watch failed: %w", err)
+	}
+	return fmt.Errorf("etcd watch stream closed")
+}
+
+// keyValue is a key-value pair as returned by the JSON gateway, which
+// base64-encodes bytes fields
+type keyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func (s *Source) keyRange() map[string]string {
+	return map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(s.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixEnd([]byte(s.Prefix))),
+	}
+}
+
+func (s *Source) configKey(key string) string {
+	key = strings.Trim(strings.TrimPrefix(key, s.Prefix), "/")
+	return strings.ReplaceAll(key, "/", ".")
+}
+
+func (s *Source) post(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.Endpoint, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// prefixEnd returns the range end that selects every key starting with
+// prefix, as etcd's clientv3.WithPrefix does
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// All 0xff: select to the end of the keyspace
+	return []byte{0}
+}
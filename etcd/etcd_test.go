@@ -0,0 +1,77 @@
+package etcd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func newFakeEtcd(values map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			var kvs []string
+			for k, v := range values {
+				kvs = append(kvs, fmt.Sprintf(`{"key": %q, "value": %q}`, b64(k), b64(v)))
+			}
+			fmt.Fprintf(w, `{"header": {}, "kvs": [%s], "count": "%d"}`, strings.Join(kvs, ","), len(kvs))
+		case "/v3/watch":
+			fmt.Fprintln(w, `{"result": {"header": {}, "created": true}}`)
+			fmt.Fprintf(w, `{"result": {"header": {}, "events": [{"kv": {"key": %q, "value": %q}}]}}`+"\n", b64("/app/port"), b64("9090"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestEtcdSource(t *testing.T) {
+	server := newFakeEtcd(map[string]string{
+		"/app/port":         "8080",
+		"/app/database/url": "postgres://etcd/db",
+	})
+	defer server.Close()
+
+	type Config struct {
+		Port     int    `cfg:"port"`
+		Database string `cfg:"database.url"`
+	}
+
+	config := &Config{}
+	if err := configflow.New().AddSource(New(server.URL, "/app/")).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Port != 8080 || config.Database != "postgres://etcd/db" {
+		t.Errorf("Unexpected config from etcd: %+v", config)
+	}
+}
+
+func TestEtcdWatch(t *testing.T) {
+	server := newFakeEtcd(nil)
+	defer server.Close()
+
+	changes := 0
+	err := New(server.URL, "/app/").Watch(context.Background(), func() { changes++ })
+	if err == nil || !strings.Contains(err.Error(), "stream closed") {
+		t.Errorf("Expected stream closed error, got: %v", err)
+	}
+	if changes != 1 {
+		t.Errorf("Expected one change notification, got %d", changes)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	if got := string(prefixEnd([]byte("/app/"))); got != "/app0" {
+		t.Errorf("Expected /app0, got %q", got)
+	}
+}
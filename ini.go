@@ -0,0 +1,101 @@
+package configflow
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseINI parses INI data into a nested map. Keys inside a [section]
+// become section.key once flattened; keys before the first section stay
+// at the top level. Lines starting with ; or # are comments. Quoted values
+// are kept as strings, other values go through parseValue.
+func parseINI(data []byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	current := result
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNo)
+			}
+			section, ok := result[name].(map[string]interface{})
+			if !ok {
+				section = make(map[string]interface{})
+				result[name] = section
+			}
+			current = section
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNo)
+		}
+
+		parsed, err := parseINIValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		current[key] = parsed
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseINIValue unquotes quoted values and strips inline comments from
+// unquoted ones. An inline comment starts at a ; or # preceded by
+// whitespace, so values like abc#123 or http://host/a;b are kept whole.
+// Only a comment may follow the closing quote of a quoted value.
+func parseINIValue(value string) (interface{}, error) {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		quote := value[0]
+		end := 1
+		for end < len(value) && value[end] != quote {
+			if quote == '"' && value[end] == '\\' {
+				end++ // Skip the escaped character
+			}
+			end++
+		}
+		if end >= len(value) {
+			return nil, fmt.Errorf("unterminated quoted value")
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+			return nil, fmt.Errorf("unexpected text after quoted value: %s", rest)
+		}
+		if quote == '"' {
+			return strconv.Unquote(value[:end+1])
+		}
+		return value[1:end], nil
+	}
+
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = strings.TrimSpace(value[:i])
+			break
+		}
+	}
+	return parseValue(value), nil
+}
@@ -0,0 +1,95 @@
+package configflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestINIFile(t *testing.T) {
+	type Config struct {
+		Name     string `cfg:"name"`
+		Host     string `cfg:"server.host"`
+		Port     int    `cfg:"server.port"`
+		Banner   string `cfg:"server.banner"`
+		URL      string `cfg:"database.url"`
+		Password string `cfg:"database.password"`
+	}
+
+	content := `; global settings
+name = demo
+
+[server]
+host = localhost
+port = 8080 ; inline comment
+banner = "say \"hi\"" # quoted, then a comment
+
+# database section
+[database]
+url = "postgres://localhost/app"
+password = 'p;ss#word'
+`
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config := &Config{}
+	if err := New().AddFile(path).Load(config); err != nil {
+		t.Fatalf("Failed to load INI config: %v", err)
+	}
+
+	if config.Name != "demo" {
+		t.Errorf("Expected top-level name 'demo', got %s", config.Name)
+	}
+	if config.Host != "localhost" || config.Port != 8080 {
+		t.Errorf("Expected server localhost:8080, got %s:%d", config.Host, config.Port)
+	}
+	if config.Banner != `say "hi"` {
+		t.Errorf("Expected escaped quotes kept before a comment, got %s", config.Banner)
+	}
+	if config.URL != "postgres://localhost/app" {
+		t.Errorf("Expected quoted database URL, got %s", config.URL)
+	}
+	if config.Password != "p;ss#word" {
+		t.Errorf("Expected quoted password to keep comment characters, got %s", config.Password)
+	}
+}
+
+func TestINIInvalid(t *testing.T) {
+	if _, err := parseINI([]byte("[server\nport=1\n")); err == nil {
+		t.Error("Expected error for unterminated section header")
+	}
+	if _, err := parseINI([]byte("just a line\n")); err == nil {
+		t.Error("Expected error for line without '='")
+	}
+	_, err := parseINI([]byte("name = demo\nkey = \"a\" b\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: unexpected text after quoted value") {
+		t.Errorf("Expected error for text after quoted value, got: %v", err)
+	}
+	if _, err := parseINI([]byte("key = \"a\n")); err == nil {
+		t.Error("Expected error for unterminated quoted value")
+	}
+}
+
+func TestINICommentCharactersInValues(t *testing.T) {
+	content := "password=abc#123\nurl=http://host/a;b\ncolor = #fff\nport = 8080 # inline\nname = demo\t; inline\n"
+	data, err := parseINI([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to parse INI: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"password": "abc#123",
+		"url":      "http://host/a;b",
+		"color":    "#fff",
+		"port":     int64(8080),
+		"name":     "demo",
+	}
+	for key, want := range expected {
+		if data[key] != want {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", key, want, want, data[key], data[key])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package configflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// interpolate expands environment references in s. Supported forms are
+// ${VAR}, ${VAR:-fallback} which uses fallback when VAR is unset or empty,
+// and ${VAR:?message} which fails with message in that case. Fallbacks may
+// contain references themselves.
+func interpolate(s string) (string, error) {
+	var out strings.Builder
+
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+		out.WriteString(s[:start])
+
+		// Find the matching brace, allowing nested references
+		depth := 0
+		end := -1
+		for i := start + 2; i < len(s); i++ {
+			if s[i] == '{' && s[i-1] == '$' {
+				depth++
+			} else if s[i] == '}' {
+				if depth == 0 {
+					end = i
+					break
+				}
+				depth--
+			}
+		}
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", s)
+		}
+
+		value, err := expandReference(s[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// expandReference resolves the body of a single ${...} reference
+func expandReference(expr string) (string, error) {
+	name, op, arg := expr, "", ""
+	if i := strings.Index(expr, ":"); i >= 0 && i+1 < len(expr) && (expr[i+1] == '-' || expr[i+1] == '?') {
+		name, op, arg = expr[:i], expr[i:i+2], expr[i+2:]
+	}
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in ${%s}", expr)
+	}
+
+	if value := os.Getenv(name); value != "" || op == "" {
+		return value, nil
+	}
+
+	if op == ":?" {
+		if arg == "" {
+			arg = "not set"
+		}
+		return "", fmt.Errorf("%s: %s", name, arg)
+	}
+	return interpolate(arg)
+}
+
+// interpolateValue expands references in strings, including those inside
+// lists and maps
+func interpolateValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return interpolate(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded, err := interpolateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = expanded
+		}
+		return items, nil
+	case map[string]interface{}:
+		entries := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			expanded, err := interpolateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			entries[k] = expanded
+		}
+		return entries, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,104 @@
+package configflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("IP_HOST", "db.local")
+	t.Setenv("IP_EMPTY", "")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"${IP_HOST}:5432", "db.local:5432"},
+		{"${IP_MISSING}", ""},
+		{"${IP_MISSING:-localhost}", "localhost"},
+		{"${IP_EMPTY:-localhost}", "localhost"},
+		{"${IP_HOST:-localhost}", "db.local"},
+		{"${IP_MISSING:-${IP_HOST:-x}}/app", "db.local/app"},
+		{"${IP_MISSING:-{literal}}", "{literal}"},
+	}
+
+	for _, test := range tests {
+		result, err := interpolate(test.input)
+		if err != nil {
+			t.Errorf("interpolate(%q) failed: %v", test.input, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("interpolate(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestInterpolateRequired(t *testing.T) {
+	t.Setenv("IP_PORT", "5432")
+
+	if result, err := interpolate("${IP_PORT:?port is required}"); err != nil || result != "5432" {
+		t.Errorf("Expected set variable to expand, got %q, %v", result, err)
+	}
+
+	_, err := interpolate("${IP_MISSING:?port is required}")
+	if err == nil || !strings.Contains(err.Error(), "IP_MISSING: port is required") {
+		t.Errorf("Expected required error, got: %v", err)
+	}
+
+	if _, err := interpolate("${IP_PORT"); err == nil {
+		t.Error("Expected error for unterminated reference")
+	}
+}
+
+func TestLoaderInterpolation(t *testing.T) {
+	t.Setenv("IP_DB_HOST", "db.internal")
+
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddYAML("host: ${IP_DB_HOST:-localhost}\nport: ${IP_DB_PORT:-5432}").
+		EnableInterpolation().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Host != "db.internal" || config.Port != 5432 {
+		t.Errorf("Expected db.internal:5432, got %s:%d", config.Host, config.Port)
+	}
+
+	err = New().
+		AddYAML("host: ${IP_DB_USER:?database user is required}").
+		EnableInterpolation().
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "database user is required") {
+		t.Errorf("Expected required variable error, got: %v", err)
+	}
+}
+
+func TestInterpolationIgnoresEnvValues(t *testing.T) {
+	t.Setenv("IP_WEIRD", "abc${oops")
+	t.Setenv("IP_DB_HOST", "db.internal")
+
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	config := &Config{}
+	err := New().
+		AddYAML("host: ${IP_DB_HOST:-localhost}").
+		AddEnv().
+		EnableInterpolation().
+		Load(config)
+	if err != nil {
+		t.Fatalf("Expected unrelated env value to be left alone, got: %v", err)
+	}
+	if config.Host != "db.internal" {
+		t.Errorf("Expected db.internal, got %s", config.Host)
+	}
+}
@@ -0,0 +1,71 @@
+package configflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	input := `{
+  // server settings
+  "url": "http://example.com/path", /* inline */
+  "quote": "say \"//not a comment\"",
+  "list": [1, 2,],
+}`
+
+	got := string(stripJSONC([]byte(input)))
+	if !json.Valid([]byte(got)) {
+		t.Fatalf("Expected valid JSON, got:\n%s", got)
+	}
+	for _, want := range []string{`"http://example.com/path"`, `"say \"//not a comment\""`, `[1, 2]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %s, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "server settings") || strings.Contains(got, "inline") {
+		t.Errorf("Expected comments to be removed, got:\n%s", got)
+	}
+}
+
+func TestJSONCFile(t *testing.T) {
+	type Config struct {
+		Port int    `cfg:"server.port"`
+		Host string `cfg:"server.host"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.jsonc")
+	content := `{
+  // the HTTP server
+  "server": {
+    "host": "localhost", // trailing comment
+    "port": 8080,
+  },
+}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config := &Config{}
+	if err := New().AddFile(path).Load(config); err != nil {
+		t.Fatalf("Failed to load JSONC config: %v", err)
+	}
+
+	if config.Port != 8080 || config.Host != "localhost" {
+		t.Errorf("Expected localhost:8080, got %s:%d", config.Host, config.Port)
+	}
+
+	// .json files are only parsed as JSONC when enabled
+	jsonPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(jsonPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := New().AddFile(jsonPath).Load(&Config{}); err == nil {
+		t.Error("Expected plain JSON parser to reject comments")
+	}
+	if err := New().AddFile(jsonPath).EnableJSONC().Load(&Config{}); err != nil {
+		t.Errorf("Expected EnableJSONC to accept comments, got: %v", err)
+	}
+}
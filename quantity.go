@@ -0,0 +1,57 @@
+package configflow
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// quantitySuffixes maps the suffixes accepted by the quantity option to
+// their multipliers: decimal SI prefixes and binary IEC prefixes
+var quantitySuffixes = map[string]int64{
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// parseQuantity converts a quantity such as "1.5k" or "2Mi" into an int64.
+// The number may be fractional as long as the result is a whole number.
+// Suffixes are case-sensitive, so "K" or "m" are rejected rather than
+// guessed at.
+func parseQuantity(value interface{}) (int64, error) {
+	s := strings.TrimSpace(fmt.Sprintf("%v", value))
+
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') && s[i-1] != '.' {
+		i--
+	}
+	number, suffix := s[:i], s[i:]
+
+	multiplier := int64(1)
+	if suffix != "" {
+		m, ok := quantitySuffixes[suffix]
+		if !ok {
+			return 0, fmt.Errorf("invalid quantity %q: unknown suffix %q (use k, M, G, T, P, E or Ki, Mi, Gi, Ti, Pi, Ei)", s, suffix)
+		}
+		multiplier = m
+	}
+
+	n, ok := new(big.Rat).SetString(number)
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	n.Mul(n, new(big.Rat).SetInt64(multiplier))
+	if !n.IsInt() || !n.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid quantity %q: not a whole number within int64", s)
+	}
+	return n.Num().Int64(), nil
+}
@@ -0,0 +1,60 @@
+package configflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected int64
+	}{
+		{"1.5k", 1500},
+		{"2Mi", 2 * 1024 * 1024},
+		{"3G", 3000000000},
+		{"0.5Ki", 512},
+		{"42", 42},
+		{int64(7), 7},
+	}
+
+	for _, test := range tests {
+		n, err := parseQuantity(test.input)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.input, err)
+			continue
+		}
+		if n != test.expected {
+			t.Errorf("%v: expected %d, got %d", test.input, test.expected, n)
+		}
+	}
+
+	for _, input := range []string{"2K", "1x", "1.5", "k", "9Ei"} {
+		if _, err := parseQuantity(input); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestQuantityOption(t *testing.T) {
+	type Config struct {
+		Throughput int64 `cfg:"throughput,quantity"`
+		Buffer     int   `cfg:"buffer,quantity" default:"64Ki"`
+	}
+
+	config := &Config{}
+	if err := New().AddMap(map[string]interface{}{"throughput": "1.5k"}).Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Throughput != 1500 {
+		t.Errorf("Expected throughput 1500, got %d", config.Throughput)
+	}
+	if config.Buffer != 64*1024 {
+		t.Errorf("Expected default buffer 65536, got %d", config.Buffer)
+	}
+
+	err := New().AddMap(map[string]interface{}{"throughput": "2mb"}).Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "unknown suffix") {
+		t.Errorf("Expected bad suffix error, got: %v", err)
+	}
+}
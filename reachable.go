@@ -0,0 +1,49 @@
+package configflow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultReachableTimeout bounds each reachable check when none is given
+const defaultReachableTimeout = 3 * time.Second
+
+// CheckReachable enables the "reachable" validator, which sends a HEAD
+// request to a URL value and fails if no response arrives within timeout
+// or the server answers with a 5xx status. It is opt-in because it does
+// network I/O during Load; without it the rule is ignored. A nil client
+// uses http.DefaultClient and a zero timeout uses 3s.
+func (l *Loader) CheckReachable(client *http.Client, timeout time.Duration) *Loader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if timeout <= 0 {
+		timeout = defaultReachableTimeout
+	}
+	l.validators["reachable"] = reachableValidator(client, timeout)
+	return l
+}
+
+func reachableValidator(client *http.Client, timeout time.Duration) ValidatorFunc {
+	return func(value interface{}, param string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%v", value), nil)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("URL is not reachable: %w", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("URL is not healthy: %s", resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package configflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReachableValidator(t *testing.T) {
+	type Config struct {
+		Endpoint string `cfg:"endpoint" validate:"url,reachable"`
+	}
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD request, got %s", r.Method)
+		}
+	}))
+	defer up.Close()
+
+	config := &Config{}
+	err := New().
+		AddMap(map[string]interface{}{"endpoint": up.URL}).
+		CheckReachable(up.Client(), time.Second).
+		Load(config)
+	if err != nil {
+		t.Errorf("Expected running server to be reachable, got: %v", err)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downURL := down.URL
+	down.Close()
+
+	err = New().
+		AddMap(map[string]interface{}{"endpoint": downURL}).
+		CheckReachable(nil, time.Second).
+		Load(&Config{})
+	if err == nil || !strings.Contains(err.Error(), "not reachable") {
+		t.Errorf("Expected stopped server to be unreachable, got: %v", err)
+	}
+
+	// Without CheckReachable the rule does no network I/O
+	if err := New().AddMap(map[string]interface{}{"endpoint": downURL}).Load(&Config{}); err != nil {
+		t.Errorf("Expected reachable to be skipped when not enabled, got: %v", err)
+	}
+}
+
+func TestReachableValidatorServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := reachableValidator(server.Client(), time.Second)(server.URL, "")
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected 5xx response to fail, got: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package configflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sampleField is a leaf entry of a generated sample config
+type sampleField struct {
+	key     string
+	value   interface{}
+	comment string
+}
+
+// GenerateSample renders a sample config file for the given struct in
+// "yaml" or "json" format. Each field is written under its cfg key with
+// its default value; in YAML the `comment` tag becomes a comment above the
+// key. Nested structs are walked recursively, with a cfg tag on the
+// struct field acting as a key prefix.
+func GenerateSample(config interface{}, format string) ([]byte, error) {
+	v := reflect.ValueOf(config)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct or pointer to struct")
+	}
+
+	fields, err := collectSampleFields(New(), v, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return renderYAMLSample(fields)
+	case "json":
+		return renderJSONSample(fields)
+	}
+	return nil, fmt.Errorf("unsupported sample format: %s", format)
+}
+
+func collectSampleFields(l *Loader, v reflect.Value, prefix string) ([]sampleField, error) {
+	var fields []sampleField
+	t := v.Type()
+
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		cfg := l.getFieldConfig(fieldType)
+		if cfg.skip {
+			continue
+		}
+		key := cfg.cfgKey
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		}
+
+		field := v.Field(i)
+		if isNestedStruct(field.Type()) {
+			nestedPrefix := prefix
+			if cfg.cfgKey != "" {
+				nestedPrefix = key
+			}
+			nested, err := collectSampleFields(l, field, nestedPrefix)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		if cfg.cfgKey == "" {
+			continue
+		}
+
+		value := reflect.New(field.Type()).Elem()
+		value.Set(field)
+		if cfg.defaultJSON != "" {
+			if err := json.Unmarshal([]byte(cfg.defaultJSON), value.Addr().Interface()); err != nil {
+				return nil, fmt.Errorf("failed to parse default-json for field %s: %w", fieldType.Name, err)
+			}
+		} else if cfg.defaultValue != "" {
+			if err := l.setDefault(value, cfg.defaultValue); err != nil {
+				return nil, fmt.Errorf("failed to set default for field %s: %w", fieldType.Name, err)
+			}
+		}
+
+		fields = append(fields, sampleField{
+			key:     key,
+			value:   sampleValue(value),
+			comment: fieldType.Tag.Get("comment"),
+		})
+	}
+
+	return fields, nil
+}
+
+// sampleValue returns the value as it should appear in a config file
+func sampleValue(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return sampleValue(v.Elem())
+	}
+	return v.Interface()
+}
+
+func renderYAMLSample(fields []sampleField) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, f := range fields {
+		parts := strings.Split(f.key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			node = yamlChild(node, part)
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1], HeadComment: f.comment}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(f.value); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", f.key, err)
+		}
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlChild returns the mapping node stored under key, creating it if needed
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.MappingNode {
+			return node.Content[i+1]
+		}
+	}
+
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+func renderJSONSample(fields []sampleField) ([]byte, error) {
+	root := make(map[string]interface{})
+
+	for _, f := range fields {
+		parts := strings.Split(f.key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = f.value
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
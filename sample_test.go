@@ -0,0 +1,69 @@
+package configflow
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSample(t *testing.T) {
+	type Database struct {
+		URL      string `cfg:"url" comment:"Database connection string"`
+		MaxConns int    `cfg:"max_connections" default:"10"`
+	}
+	type Config struct {
+		Port     int           `cfg:"server.port" default:"8080" comment:"Port the HTTP server listens on"`
+		Timeout  time.Duration `cfg:"server.timeout" default:"30s"`
+		Database Database      `cfg:"database"`
+		Internal string
+	}
+
+	out, err := GenerateSample(&Config{}, "yaml")
+	if err != nil {
+		t.Fatalf("Failed to generate sample: %v", err)
+	}
+	sample := string(out)
+
+	for _, want := range []string{
+		"# Port the HTTP server listens on\n  port: 8080",
+		"timeout: 30s",
+		"# Database connection string",
+		"max_connections: 10",
+	} {
+		if !strings.Contains(sample, want) {
+			t.Errorf("Expected sample to contain %q, got:\n%s", want, sample)
+		}
+	}
+	if strings.Contains(sample, "Internal") {
+		t.Errorf("Expected untagged field to be omitted, got:\n%s", sample)
+	}
+
+	// The sample loads back into the same values
+	config := &Config{}
+	if err := New().AddYAML(sample).Load(config); err != nil {
+		t.Fatalf("Failed to load generated sample: %v", err)
+	}
+	if config.Port != 8080 || config.Timeout != 30*time.Second {
+		t.Errorf("Expected sample defaults to load back, got %d/%v", config.Port, config.Timeout)
+	}
+}
+
+func TestGenerateSampleJSON(t *testing.T) {
+	type Config struct {
+		Port int `cfg:"server.port" default:"8080"`
+	}
+
+	out, err := GenerateSample(Config{}, "json")
+	if err != nil {
+		t.Fatalf("Failed to generate sample: %v", err)
+	}
+
+	var decoded map[string]map[string]int
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON sample, got: %v\n%s", err, out)
+	}
+	if decoded["server"]["port"] != 8080 {
+		t.Errorf("Expected server.port 8080, got %v", decoded)
+	}
+}
@@ -0,0 +1,41 @@
+package configflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// templateData is the dot of config templates, so files can use both
+// {{ .Env "REGION" }} and {{ env "REGION" }}
+type templateData struct{}
+
+// Env returns the value of the environment variable name
+func (templateData) Env(name string) string {
+	return os.Getenv(name)
+}
+
+var templateFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// renderTemplate executes data as a text/template before it is parsed
+func renderTemplate(data []byte, name string) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, templateData{}); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return out.Bytes(), nil
+}
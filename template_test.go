@@ -0,0 +1,48 @@
+package configflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnableTemplating(t *testing.T) {
+	t.Setenv("TPL_REGION", "eu-west-1")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `region: {{ .Env "TPL_REGION" }}
+bucket: assets-{{ env "TPL_REGION" }}
+tier: {{ env "TPL_TIER" | default "standard" }}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	type Config struct {
+		Region string `cfg:"region"`
+		Bucket string `cfg:"bucket"`
+		Tier   string `cfg:"tier"`
+	}
+
+	config := &Config{}
+	if err := New().AddFile(path).EnableTemplating().Load(config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Region != "eu-west-1" || config.Bucket != "assets-eu-west-1" || config.Tier != "standard" {
+		t.Errorf("Unexpected rendered config: %+v", config)
+	}
+}
+
+func TestEnableTemplatingError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	if err := os.WriteFile(path, []byte("region: {{ .Env }"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	err := New().AddFile(path).EnableTemplating().Load(&struct{}{})
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected template error naming %s, got: %v", path, err)
+	}
+}
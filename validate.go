@@ -0,0 +1,62 @@
+package configflow
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValidateStruct checks an already populated config struct against its
+// validate tags using the built-in validators, without any sources. It
+// descends into nested structs, including non-nil pointers to them. As
+// with Load, zero-valued fields count as absent: only rules like required
+// apply to them.
+func ValidateStruct(config interface{}) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a struct or pointer to struct")
+	}
+	return New().validateStruct(v)
+}
+
+func (l *Loader) validateStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() || l.getFieldConfig(fieldType).skip {
+			continue
+		}
+
+		nested := field
+		if nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && isNestedStruct(nested.Type()) {
+			if err := l.validateStruct(nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rules := fieldType.Tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+
+		var value interface{}
+		if !field.IsZero() {
+			value = nested.Interface()
+		}
+		ctx := fieldContext{name: fieldType.Name, field: field, parent: v}
+		if _, err := l.validateField(ctx, value, rules); err != nil {
+			return err
+		}
+		if err := l.validateDeferred(ctx, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
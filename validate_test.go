@@ -0,0 +1,46 @@
+package configflow
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateStruct(t *testing.T) {
+	type Pool struct {
+		Size int `validate:"range:1,100"`
+	}
+	type Database struct {
+		URL  string `validate:"required,url"`
+		Pool *Pool
+	}
+	type Config struct {
+		Name     string `validate:"required"`
+		Database Database
+	}
+
+	valid := Config{
+		Name:     "api",
+		Database: Database{URL: "postgres://localhost/app", Pool: &Pool{Size: 10}},
+	}
+	if err := ValidateStruct(&valid); err != nil {
+		t.Errorf("Expected valid config to pass, got: %v", err)
+	}
+
+	invalid := valid
+	invalid.Database.Pool = &Pool{Size: 500}
+	err := ValidateStruct(invalid)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "Size" {
+		t.Fatalf("Expected ValidationError for nested field Size, got: %v", err)
+	}
+
+	missing := valid
+	missing.Database.URL = ""
+	if err := ValidateStruct(&missing); err == nil {
+		t.Error("Expected missing required URL to fail")
+	}
+
+	if err := ValidateStruct("not a struct"); err == nil {
+		t.Error("Expected error for non-struct config")
+	}
+}
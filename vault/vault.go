@@ -0,0 +1,93 @@
+// Package vault provides a configflow source that reads a secret from
+// the HashiCorp Vault KV version 2 secrets engine over its HTTP API.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+// Source loads the key/value pairs of a KV v2 secret. Add it with
+// loader.AddSource; nested values are flattened under Prefix, so with
+// Prefix "database" the secret key "password" becomes "database.password".
+// Sources are merged in the order they are added, so add it after files
+// and env for its secrets to override them.
+type Source struct {
+	Address string // Vault address, defaults to $VAULT_ADDR
+	Token   string // Vault token, defaults to $VAULT_TOKEN
+	Mount   string // mount path of the KV engine, defaults to "secret"
+	Path    string // secret path below the mount
+	Prefix  string // key prefix for the secret's values
+	Client  *http.Client
+}
+
+// New creates a source for the secret at path, keyed under prefix
+func New(path, prefix string) *Source {
+	return &Source{Path: path, Prefix: prefix}
+}
+
+func (s *Source) Priority() int { return 3 }
+
+func (s *Source) Load() (map[string]interface{}, error) {
+	return s.LoadContext(context.Background())
+}
+
+func (s *Source) LoadContext(ctx context.Context) (map[string]interface{}, error) {
+	address := s.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	token := s.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("vault address not set")
+	}
+	mount := s.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+
+	url := strings.TrimRight(address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.TrimLeft(s.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", s.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read vault secret %s: %s", s.Path, resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret %s: %w", s.Path, err)
+	}
+
+	data := body.Data.Data
+	if s.Prefix != "" {
+		data = map[string]interface{}{s.Prefix: data}
+	}
+	return (&configflow.MapSource{Data: data}).Load()
+}
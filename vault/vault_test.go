@@ -0,0 +1,52 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Piyu-Pika/configflow"
+)
+
+func TestVaultSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/app/db" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"username": "app", "password": "s3cret"}, "metadata": {"version": 3}}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	type Config struct {
+		Username string `cfg:"database.username"`
+		Password string `cfg:"database.password,secret"`
+	}
+
+	config := &Config{}
+	err := configflow.New().
+		AddMap(map[string]interface{}{"database.username": "default"}).
+		AddSource(New("app/db", "database")).
+		Load(config)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Username != "app" || config.Password != "s3cret" {
+		t.Errorf("Unexpected config from vault: %+v", config)
+	}
+
+	// A rejected token fails the load
+	source := New("app/db", "database")
+	source.Token = "wrong"
+	if err := configflow.New().AddSource(source).Load(&Config{}); err == nil {
+		t.Error("Expected error for a rejected token")
+	}
+}